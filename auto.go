@@ -0,0 +1,715 @@
+package auto
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"strings"
+	"time"
+)
+
+// ClickLeftMouseAt moves the mouse to screen coordinates x,y and clicks the
+// left mouse button, i.e. presses and releases it.
+func ClickLeftMouseAt(x, y int) error {
+	return clickAt(x, y, LeftMouseButton)
+}
+
+// ClickLeftMouse clicks the left mouse button, i.e. presses and releases it.
+func ClickLeftMouse() error {
+	return click(LeftMouseButton)
+}
+
+// PressLeftMouseAt moves the mouse to screen coordinates x,y and presses the
+// left mouse button down. Call ReleaseLeftMouse or ReleaseLeftMouseAt to
+// release the button.
+func PressLeftMouseAt(x, y int) error {
+	return new(InputSequence).MoveMouseTo(x, y).PressMouse(LeftMouseButton).Send()
+}
+
+// PressLeftMouse presses the left mouse button down. Call ReleaseLeftMouse or
+// ReleaseLeftMouseAt to release the button.
+func PressLeftMouse() error {
+	return new(InputSequence).PressMouse(LeftMouseButton).Send()
+}
+
+// ReleaseLeftMouseAt moves the mouse to screen coordinates x,y and releases the
+// left mouse button. You probably want to press it before, using
+// PressLeftMouseAt or PressLeftMouse.
+func ReleaseLeftMouseAt(x, y int) error {
+	return new(InputSequence).MoveMouseTo(x, y).ReleaseMouse(LeftMouseButton).Send()
+}
+
+// ReleaseLeftMouse releases the left mouse button. You probably want to press
+// it before, using PressLeftMouseAt or PressLeftMouse.
+func ReleaseLeftMouse() error {
+	return new(InputSequence).ReleaseMouse(LeftMouseButton).Send()
+}
+
+// ClickRightMouseAt moves the mouse to screen coordinates x,y and clicks the
+// right mouse button, i.e. presses and releases it.
+func ClickRightMouseAt(x, y int) error {
+	return clickAt(x, y, RightMouseButton)
+}
+
+// ClickRightMouse clicks the right mouse button, i.e. presses and releases it.
+func ClickRightMouse() error {
+	return click(RightMouseButton)
+}
+
+// PressRightMouseAt moves the mouse to screen coordinates x,y and presses the
+// right mouse button down. Call ReleaseRightMouse or ReleaseRightMouseAt to
+// release the button.
+func PressRightMouseAt(x, y int) error {
+	return new(InputSequence).MoveMouseTo(x, y).PressMouse(RightMouseButton).Send()
+}
+
+// PressRightMouse presses the right mouse button down. Call ReleaseRightMouse or
+// ReleaseRightMouseAt to release the button.
+func PressRightMouse() error {
+	return new(InputSequence).PressMouse(RightMouseButton).Send()
+}
+
+// ReleaseRightMouseAt moves the mouse to screen coordinates x,y and releases the
+// right mouse button. You probably want to press it before, using
+// PressRightMouseAt or PressRightMouse.
+func ReleaseRightMouseAt(x, y int) error {
+	return new(InputSequence).MoveMouseTo(x, y).ReleaseMouse(RightMouseButton).Send()
+}
+
+// ReleaseRightMouse releases the right mouse button. You probably want to press
+// it before, using PressRightMouseAt or PressRightMouse.
+func ReleaseRightMouse() error {
+	return new(InputSequence).ReleaseMouse(RightMouseButton).Send()
+}
+
+// ClickMiddleMouseAt moves the mouse to screen coordinates x,y and clicks the
+// middle mouse button, i.e. presses and releases it.
+func ClickMiddleMouseAt(x, y int) error {
+	return clickAt(x, y, MiddleMouseButton)
+}
+
+// ClickMiddleMouse clicks the middle mouse button, i.e. presses and releases it.
+func ClickMiddleMouse() error {
+	return click(MiddleMouseButton)
+}
+
+// PressMiddleMouseAt moves the mouse to screen coordinates x,y and presses the
+// middle mouse button down. Call ReleaseMiddleMouse or ReleaseMiddleMouseAt to
+// release the button.
+func PressMiddleMouseAt(x, y int) error {
+	return new(InputSequence).MoveMouseTo(x, y).PressMouse(MiddleMouseButton).Send()
+}
+
+// PressMiddleMouse presses the middle mouse button down. Call ReleaseMiddleMouse or
+// ReleaseMiddleMouseAt to release the button.
+func PressMiddleMouse() error {
+	return new(InputSequence).PressMouse(MiddleMouseButton).Send()
+}
+
+// ReleaseMiddleMouseAt moves the mouse to screen coordinates x,y and releases the
+// middle mouse button. You probably want to press it before, using
+// PressMiddleMouseAt or PressMiddleMouse.
+func ReleaseMiddleMouseAt(x, y int) error {
+	return new(InputSequence).MoveMouseTo(x, y).ReleaseMouse(MiddleMouseButton).Send()
+}
+
+// ReleaseMiddleMouse releases the middle mouse button. You probably want to press
+// it before, using PressMiddleMouseAt or PressMiddleMouse.
+func ReleaseMiddleMouse() error {
+	return new(InputSequence).ReleaseMouse(MiddleMouseButton).Send()
+}
+
+// MoveMouseTo move the mouse cursor to the given screen coordinates. The move
+// is sent as mouse input, so mouse hooks and applications see it like a move
+// of the real mouse.
+func MoveMouseTo(x, y int) error {
+	return new(InputSequence).MoveMouseTo(x, y).Send()
+}
+
+// MoveMouseBy moves the mouse cursor by the given amount of pixels in x and y.
+// Positive x moves the cursor right.
+// Negative x moves the cursor left.
+// Positive y moves the cursor down.
+// Negative y moves the cursor up.
+func MoveMouseBy(dx, dy int) error {
+	return new(InputSequence).MoveMouseBy(dx, dy).Send()
+}
+
+// MoveMouseByRaw sends relative mouse motion, like a physical mouse does. Use
+// this for applications that read raw mouse input, e.g. games, which see
+// exactly dx and dy. The cursor on the other hand moves according to the
+// system's mouse speed and acceleration settings, so it usually does not move
+// by exactly dx,dy pixels. Use MoveMouseBy for that.
+func MoveMouseByRaw(dx, dy int) error {
+	return new(InputSequence).MoveMouseByRaw(dx, dy).Send()
+}
+
+// MousePosition returns the mouse position in screen coordinates.
+//
+// On Linux without an X server the position of the cursor cannot be queried.
+// In that case this returns the position that the mouse was last moved to by
+// this library.
+func MousePosition() (x, y int, err error) {
+	return driver().MousePosition()
+}
+
+// MoveMouseWheelBy rotates the mouse wheel, vertically and/or horizontally.
+// dy is the vertical rotation, dy = 1 means one tick forward, away from the
+// user.
+// dy = -1 means one tick backward, towards the user.
+// dx is the horizontal rotation. dx = 1 means one tick to the right, dx = -1
+// means one tick to the left.
+//
+// X11 only supports whole ticks. Fractional ticks are accumulated over
+// multiple calls and sent once they add up to a whole tick.
+func MoveMouseWheelBy(dx, dy float64) error {
+	return new(InputSequence).MoveMouseWheelBy(dx, dy).Send()
+}
+
+func clickAt(x, y int, b MouseButton) error {
+	return new(InputSequence).MoveMouseTo(x, y).ClickMouse(b).Send()
+}
+
+func click(b MouseButton) error {
+	return new(InputSequence).ClickMouse(b).Send()
+}
+
+// DoubleClickLeftMouseAt moves the mouse to screen coordinates x,y and double
+// clicks the left mouse button there.
+func DoubleClickLeftMouseAt(x, y int) error {
+	return clickN(new(InputSequence).MoveMouseTo(x, y), LeftMouseButton, 2)
+}
+
+// DoubleClickLeftMouse double clicks the left mouse button.
+func DoubleClickLeftMouse() error {
+	return ClickMouseN(LeftMouseButton, 2)
+}
+
+// ClickMouseN clicks the given mouse button n times in a row, e.g. n = 2 for a
+// double click or n = 3 for a triple click. n must be at least 1.
+//
+// The clicks have to be close together in time and space for the system to
+// count them as a multi-click, see DoubleClickLimits. ClickMouseN sends all
+// clicks at once, so they are always inside these limits and no other input,
+// e.g. the user moving the mouse, can come in between.
+func ClickMouseN(button MouseButton, n int) error {
+	return clickN(new(InputSequence), button, n)
+}
+
+// clickN appends n clicks to s and sends it.
+func clickN(s *InputSequence, button MouseButton, n int) error {
+	if n < 1 {
+		return fmt.Errorf("cannot click %d times, n must be at least 1", n)
+	}
+	for i := 0; i < n; i++ {
+		s.ClickMouse(button)
+	}
+	return s.Send()
+}
+
+// DoubleClickLimits returns the system settings for multi-clicks: the maximum
+// time between two clicks and the size of the rectangle, centered on the
+// first click, that the next click has to be in. Use them if you time clicks
+// yourself, ClickMouseN always stays inside these limits.
+func DoubleClickLimits() (limit time.Duration, width, height int) {
+	return doubleClickLimits()
+}
+
+// PressXButton presses the side mouse button X1 (n = 1) or X2 (n = 2) down.
+// Call ReleaseXButton to release it.
+func PressXButton(n int) error {
+	b, err := xMouseButton(n)
+	if err != nil {
+		return err
+	}
+	return new(InputSequence).PressMouse(b).Send()
+}
+
+// ReleaseXButton releases the side mouse button X1 (n = 1) or X2 (n = 2).
+func ReleaseXButton(n int) error {
+	b, err := xMouseButton(n)
+	if err != nil {
+		return err
+	}
+	return new(InputSequence).ReleaseMouse(b).Send()
+}
+
+// ClickXButton clicks the side mouse button X1 (n = 1) or X2 (n = 2), i.e.
+// presses and releases it.
+func ClickXButton(n int) error {
+	b, err := xMouseButton(n)
+	if err != nil {
+		return err
+	}
+	return click(b)
+}
+
+func xMouseButton(n int) (MouseButton, error) {
+	switch n {
+	case 1:
+		return X1MouseButton, nil
+	case 2:
+		return X2MouseButton, nil
+	}
+	return 0, fmt.Errorf("there is no X button %d, only 1 and 2", n)
+}
+
+// Type will write the given text by pressing the keys that produce its
+// characters. It will sleep the smallest, non-0 delay between two letters.
+func Type(s string) error {
+	return TypeWithDelay(s, 1)
+}
+
+// TypeWithDelay will write the given text by pressing the keys that produce
+// its characters. It will sleep the given delay between two letters.
+//
+// It uses TypeAuto, see TypeWithOptions for other ways to type. On Linux
+// without an X server the text is typed on a US keyboard layout and characters
+// that are not on it cannot be typed.
+func TypeWithDelay(s string, delay time.Duration) error {
+	return TypeWithOptions(s, TypeOptions{Delay: delay})
+}
+
+// unifyLineBreaks turns all line breaks into '\r' which we type as the Return
+// key.
+func unifyLineBreaks(s string) string {
+	s = strings.Replace(s, "\r\n", "\r", -1)
+	return strings.Replace(s, "\n", "\r", -1)
+}
+
+// PressKey presses the given key on the keyboard. You can pass key codes
+// defined in this package, named Key...
+func PressKey(key uint16) error {
+	return new(InputSequence).PressKey(key).Send()
+}
+
+// ReleaseKey releases the given key on the keyboard. You can pass key codes
+// defined in this package, named Key...
+func ReleaseKey(key uint16) error {
+	return new(InputSequence).ReleaseKey(key).Send()
+}
+
+// TypeKey presses and releases the given key on the keyboard. You can pass key
+// codes defined in this package, named Key...
+func TypeKey(key uint16) error {
+	return new(InputSequence).TypeKey(key).Send()
+}
+
+// SetOnKeyboardEvent sets a callback that is called every time a keyboard
+// event happens, i.e. a key is pressed or released. Set it to nil to stop
+// listening to keyboard events.
+//
+// On X11 calling Cancel on the event only has an effect for keys that were
+// registered with SetCancelableKeys. Listening needs version 2.1 of the X
+// Input extension. If it cannot be started, the callback is never called and
+// SetCancelableKeys returns the reason.
+func SetOnKeyboardEvent(f func(*KeyboardEvent)) {
+	driver().SetOnKeyboardEvent(f)
+}
+
+// SetOnMouseEvent sets a callback that is called every time a mouse event
+// happens, i.e. a button is pressed or released, the mouse moves or the mouse
+// wheel is rotated. Set it to nil to stop listening to mouse events.
+//
+// On X11 calling Cancel on the event only has an effect for pressing mouse
+// buttons that were registered with SetCancelableKeys. See SetOnKeyboardEvent
+// for what listening needs on X11.
+func SetOnMouseEvent(f func(*MouseEvent)) {
+	driver().SetOnMouseEvent(f)
+}
+
+// SetCancelableKeys sets the keys for which KeyboardEvent.Cancel and
+// MouseEvent.Cancel work. This is only needed on X11 and replaces the keys of
+// the previous call. Call it without arguments to make all keys uncancelable
+// again. On Windows all keys are cancelable and this does nothing, on other
+// systems it returns ErrUnsupported.
+//
+// X11 cannot intercept input in general. Instead this library grabs the given
+// keys, which means that the X server sends them only to us and freezes the
+// keyboard until we decide to either pass the key on to the focussed window or
+// drop it. For mouse buttons, pass KeyLeftButton, KeyRightButton,
+// KeyMiddleButton, KeyXButton1 or KeyXButton2. Mouse buttons are grabbed the
+// same way and freeze the mouse until the callback returns.
+//
+// A cancelled key press also drops the release of that key. Only key and
+// button presses can be cancelled. Grabs are only active while a keyboard or
+// mouse callback is set. An error is returned if a key does not exist on the
+// keyboard layout or another program already grabbed it. If a callback is set
+// but listening could not be started, e.g. because the X server does not
+// support XInput 2.1, that error is returned.
+//
+// Drivers that you set with SetDriver can support it by implementing
+// CancelableKeysDriver, for all others it does nothing.
+func SetCancelableKeys(keys ...uint16) error {
+	if d, ok := baseDriver().(CancelableKeysDriver); ok {
+		return d.SetCancelableKeys(keys...)
+	}
+	return nil
+}
+
+// SetOnClipboardChange sets a callback that is called every time the content
+// of the clipboard changes.
+//
+// On X11 this uses the XFixes extension to get notified when the owner of the
+// clipboard selection changes.
+func SetOnClipboardChange(f func()) {
+	driver().SetOnClipboardChange(f)
+}
+
+// UsePrimarySelection makes ClipboardText, SetClipboardText and
+// SetOnClipboardChange use the X11 PRIMARY selection instead of the CLIPBOARD
+// selection. PRIMARY holds the text that was last selected with the mouse and
+// is pasted with a middle click. CLIPBOARD, the default, holds the text that
+// was last copied explicitly, e.g. with Ctrl+C.
+//
+// Other systems only have one clipboard, there this does nothing. Drivers that
+// you set with SetDriver can support it by implementing
+// PrimarySelectionDriver.
+func UsePrimarySelection(primary bool) {
+	if d, ok := baseDriver().(PrimarySelectionDriver); ok {
+		d.UsePrimarySelection(primary)
+	}
+}
+
+// Rectangle is used to desribe monitor and window boundaries.
+type Rectangle struct {
+	// X is the left-most pixel.
+	X int
+	// Y is the top-most pixel.
+	Y int
+	// Width is the width in pixels.
+	Width int
+	// Height is the height in pixels.
+	Height int
+}
+
+// Monitor is a single monitor connected to your computer.
+type Monitor struct {
+	// Rectangle is the outer boundary of the monitor, in virtual screen
+	// coordinates. All monitors share this virtual coordinate system. In your
+	// operating system settings you can freely move monitors around in this
+	// coordinate system to represent the real world layout of your monitors.
+	// For example, you might put two monitors side by side or on top of each
+	// other.
+	Rectangle
+	// WorkArea is the monitor area that is not covered by the task bar.
+	WorkArea Rectangle
+	// Primary is true if this is the current default/primary monitor.
+	Primary bool
+}
+
+// KeyboardEvent is given to the callback passed to SetOnKeyboardEvent. Every
+// time a keyboard event is triggered by either the user or programmatically
+// (e.g. by this library), a KeyboardEvent is sent. Key is the virtual key
+// code, see the Key... constants defined in this library. Down indicates
+// whether the key is presed down (true) or released (false). Injected is true
+// if the key event was generated programmatically.
+type KeyboardEvent struct {
+	Key       uint16
+	Down      bool
+	Injected  bool
+	cancelled bool
+}
+
+// Cancel stops the event from being handled further. That means the currently
+// focussed window will not receive the event. On X11 this only works for keys
+// registered with SetCancelableKeys.
+func (e *KeyboardEvent) Cancel() {
+	e.cancelled = true
+}
+
+// MouseEvent is given to the callback passed to SetOnMouseEvent. Every time a
+// mouse event is triggered by either the user or programmatically (e.g. by
+// this library), a MouseEvent is sent. Type is the concrete event type
+// (button, move or wheel event). X and Y are the screen coordinates in monitor
+// space. These can be negative, e.g. if you place your second monitor left of
+// the primary monitor (and tell Windows via its settings). Wheel is the amount
+// of ticks the mouse wheel has rotated. This is only set for events MouseWheel
+// and MouseWheelHorizontal, otherwise it is 0. Injected is true if the key
+// event was generated programmatically.
+type MouseEvent struct {
+	Type      MouseEventType
+	X         int
+	Y         int
+	Wheel     float64
+	Injected  bool
+	cancelled bool
+}
+
+// Cancel stops the event from being handled further. That means the currently
+// focussed window will not receive the event. On X11 this only works for
+// button presses registered with SetCancelableKeys.
+func (e *MouseEvent) Cancel() {
+	e.cancelled = true
+}
+
+// MouseEventType is the concrete type of a MouseEvent.
+type MouseEventType int
+
+// These are the available MosueEventTypes. Mouse down and up events are sent
+// when a mouse button is pressed down and released respectively. The XButton
+// events are for the side buttons that some mice have. MouseMove is
+// sent when the mouse moves. MouseWheel is sent when the regular vertical
+// mouse wheel on a desktop mouse is scrolled or when a touch pad is scrolled
+// up or down. MouseWheelHorizontal is sent when a horizontal wheel is
+// scrolled. These typically do not exist on regular desktop mouse devices.
+// This can be triggered with a touch pad scroll from left to right or vice
+// versa.
+//
+// The values are the Windows message codes of the events. Windows uses the same
+// message for both X buttons, for them the high word holds the X button number.
+const (
+	LeftMouseDown        MouseEventType = 0x0201
+	LeftMouseUp                         = 0x0202
+	RightMouseDown                      = 0x0204
+	RightMouseUp                        = 0x0205
+	MiddleMouseDown                     = 0x0207
+	MiddleMouseUp                       = 0x0208
+	MouseMove                           = 0x0200
+	MouseWheel                          = 0x020A
+	MouseWheelHorizontal                = 0x020E
+	XButton1Down                        = 0x1020B
+	XButton1Up                          = 0x1020C
+	XButton2Down                        = 0x2020B
+	XButton2Up                          = 0x2020C
+)
+
+// XButton returns 1 or 2 for the XButton events, it is the number of the X
+// button that was pressed or released. For all other events it returns 0.
+func (e *MouseEvent) XButton() int {
+	switch e.Type {
+	case XButton1Down, XButton1Up:
+		return 1
+	case XButton2Down, XButton2Up:
+		return 2
+	}
+	return 0
+}
+
+// Window is a window currently open on you system.
+type Window struct {
+	// Rectangle is the window's outer boundaries in virtual screen coordinates.
+	Rectangle
+	// Content is the window's inner boundaries in virtual screen coordinates.
+	Content Rectangle
+	// Visible is true if the window is a visual window. For background windows
+	// Visible is false.
+	Visible bool
+	// Title is the text currently displayed in the window header.
+	Title string
+	// ClassName is the name of the class of this window. Multiple windows can
+	// have the same class.
+	ClassName string
+	// Maximized is true if the window is currently maximized.
+	Maximized bool
+	// Minimized is true if the window is currently minimized.
+	Minimized bool
+	// Handle is the operating specific window handle, a HWND on Windows and
+	// an X11 window ID on Linux.
+	Handle uintptr
+}
+
+// Windows returns a list of all currently active windows.
+func Windows() ([]Window, error) {
+	return driver().Windows()
+}
+
+// ForegroundWindow returns the currently active window. If no window is active,
+// ForegroundWindow returns an error.
+func ForegroundWindow() (Window, error) {
+	return driver().ForegroundWindow()
+}
+
+// Update updates the state of the window, all fields are queried from the OS
+// again. If the state or size of a window changes, Update will poll these
+// changes.
+func (w *Window) Update() {
+	*w = driver().UpdateWindow(*w)
+}
+
+// BringToForeground tries to bring the given window to the front.
+func (w *Window) BringToForeground() error {
+	if err := driver().BringWindowToForeground(*w); err != nil {
+		return err
+	}
+	w.Update()
+	return nil
+}
+
+// Restore unminimizes a minimized window and unmaximizes a maximized window.
+func (w *Window) Restore() {
+	driver().RestoreWindow(*w)
+	w.Update()
+}
+
+// Maximize maximizes the given window.
+func (w *Window) Maximize() {
+	driver().MaximizeWindow(*w)
+	w.Update()
+}
+
+// Minimize minimizes the given window.
+func (w *Window) Minimize() {
+	driver().MinimizeWindow(*w)
+	w.Update()
+}
+
+// Hide hides the window. Call ShowWindow to show it again.
+func (w *Window) Hide() {
+	driver().HideWindow(*w)
+	w.Update()
+}
+
+// Show shows the given window. Call this to show a window that was hidden with
+// Hide.
+func (w *Window) Show() {
+	driver().ShowWindow(*w)
+	w.Update()
+}
+
+// InnerPosition reutrns the boundaries of the window content, i.e. without
+// window borders, in screen coordinates.
+func (w *Window) InnerPosition() (x, y, width, height int, err error) {
+	return driver().WindowInnerPosition(*w)
+}
+
+// SetInnerPosition sets the boundaries of the window content, i.e. without
+// window borders, in screen coordinates.
+//
+// Note that if the window is currently maximized, you might want to Restore()
+// it before calling SetInnerPosition to un-maximize it. This might only
+// restore it to a maximized state, thus you probably want to call Restore()
+// twice in that case.
+func (w *Window) SetInnerPosition(x, y, width, height int) error {
+	if err := driver().SetWindowInnerPosition(*w, x, y, width, height); err != nil {
+		return err
+	}
+	w.Update()
+	return nil
+}
+
+// OuterPosition returns the bounaries of the window border, in screen
+// coordinates.
+func (w *Window) OuterPosition() (x, y, width, height int, err error) {
+	return driver().WindowOuterPosition(*w)
+}
+
+// SetOuterPosition sets the boundaries of the window border.
+//
+// Note that if the window is currently maximized, you might want to Restore()
+// it before calling SetOuterPosition to un-maximize it. This might only
+// restore it to a maximized state, thus you probably want to call Restore()
+// twice in that case.
+func (w *Window) SetOuterPosition(x, y, width, height int) error {
+	if err := driver().SetWindowOuterPosition(*w, x, y, width, height); err != nil {
+		return err
+	}
+	w.Update()
+	return nil
+}
+
+// ClipboardText returns the contents of the clipboard as text. If the clipboard
+// is empty or does not contain text it returns "".
+func ClipboardText() (string, error) {
+	return driver().ClipboardText()
+}
+
+// SetClipboardText sets the contents of the clipboard to the given string.
+//
+// On X11 this makes the program the owner of the clipboard. A background
+// goroutine serves the text to other programs until another program sets the
+// clipboard or this program exits.
+func SetClipboardText(text string) error {
+	return driver().SetClipboardText(text)
+}
+
+// PrimaryMonitor returns the current default/primary monitor.
+func PrimaryMonitor() (Monitor, error) {
+	monitors, err := Monitors()
+	if err != nil {
+		return Monitor{}, err
+	}
+	for _, m := range monitors {
+		if m.Primary {
+			return m, nil
+		}
+	}
+	return Monitor{}, errors.New("no primary monitor found")
+}
+
+// Monitors returns all monitors currently connected to the computer.
+func Monitors() ([]Monitor, error) {
+	return driver().Monitors()
+}
+
+// CaptureScreen returns a screen shot of the given area. The area is given in
+// virtual screen coordinates. Parts of the area that lie outside the screen
+// are transparent in the image on X11.
+func CaptureScreen(x, y, width, height int) (image.Image, error) {
+	return driver().CaptureScreen(x, y, width, height)
+}
+
+// CaptureWindow returns a screen shot of the outer boundaries of the given
+// window.
+func CaptureWindow(w Window) (image.Image, error) {
+	return CaptureScreenRect(w.Rectangle)
+}
+
+// CaptureWindowContent returns a screen shot of the inner boundaries of the
+// given window.
+func CaptureWindowContent(w Window) (image.Image, error) {
+	return CaptureScreenRect(w.Content)
+}
+
+// CaptureMonitor returns a screen shot of the outer boundaries of the given
+// monitor.
+func CaptureMonitor(m Monitor) (image.Image, error) {
+	return CaptureScreenRect(m.Rectangle)
+}
+
+// CaptureScreenRect is a wrapper for CaptureScreen. It allows you to pass a
+// Monitor's WorkArea to this function instead of unwrapping the Rectangle
+// yourself.
+func CaptureScreenRect(r Rectangle) (image.Image, error) {
+	return CaptureScreen(r.X, r.Y, r.Width, r.Height)
+}
+
+// CaptureMonitors returns a screen shot of the outer hull of all the given
+// monitors. Depending on your operating system settings this may include blank
+// areas which will be transparent in the image. For example, if you have a 1200
+// pixel high monitor next to a 1080 pixel high monitor, there will a 1200-1080
+// = 120 pixel high area below the smaller monitor that is transparent.
+func CaptureMonitors(monitors []Monitor) (image.Image, error) {
+	if len(monitors) == 0 {
+		return &image.RGBA{}, errors.New("now monitor given")
+	}
+	hullLeft := monitors[0].X
+	hullTop := monitors[0].Y
+	hullRight := monitors[0].X + monitors[0].Width
+	hullBottom := monitors[0].Y + monitors[0].Height
+	for _, m := range monitors {
+		left := m.X
+		top := m.Y
+		right := m.X + m.Width
+		bottom := m.Y + m.Height
+		if left < hullLeft {
+			hullLeft = left
+		}
+		if right > hullRight {
+			hullRight = right
+		}
+		if top < hullTop {
+			hullTop = top
+		}
+		if bottom > hullBottom {
+			hullBottom = bottom
+		}
+	}
+	r := Rectangle{
+		X:      hullLeft,
+		Y:      hullTop,
+		Width:  hullRight - hullLeft,
+		Height: hullBottom - hullTop,
+	}
+	return CaptureScreenRect(r)
+}
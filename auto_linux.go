@@ -0,0 +1,1417 @@
+package auto
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode"
+)
+
+// These are the X11 pointer buttons. Buttons 4 to 7 are not real buttons, X11
+// uses them to represent mouse wheel ticks.
+const (
+	xButtonLeft    = 1
+	xButtonMiddle  = 2
+	xButtonRight   = 3
+	xWheelUp       = 4
+	xWheelDown     = 5
+	xWheelLeft     = 6
+	xWheelRight    = 7
+	xButtonBack    = 8
+	xButtonForward = 9
+)
+
+// xButtons maps the MouseButtons to X11 pointer buttons.
+var xButtons = map[MouseButton]byte{
+	LeftMouseButton:   xButtonLeft,
+	RightMouseButton:  xButtonRight,
+	MiddleMouseButton: xButtonMiddle,
+	X1MouseButton:     xButtonBack,
+	X2MouseButton:     xButtonForward,
+}
+
+// linuxDriver implements Driver for X11. On Wayland and without an X server,
+// mouse and keyboard input goes through uinput instead, see currentInjector.
+// Touch and pen input always go through uinput, see injectTouch and
+// injectPen.
+type linuxDriver struct{}
+
+var platformDriver Driver = linuxDriver{}
+
+func (linuxDriver) Inject(events ...InputEvent) error {
+	// Touch and pen input go to their virtual devices, everything else to the
+	// current injector. We keep the order by splitting the events into runs
+	// for the same device.
+	for len(events) > 0 {
+		n := 1
+		for n < len(events) && inputDevice(events[n]) == inputDevice(events[0]) {
+			n++
+		}
+		run := events[:n]
+		events = events[n:]
+
+		switch inputDevice(run[0]) {
+		case touchDevice:
+			if err := injectTouch(run); err != nil {
+				return err
+			}
+		case penDevice:
+			if err := injectPen(run); err != nil {
+				return err
+			}
+		default:
+			i, err := currentInjector()
+			if err != nil {
+				return err
+			}
+			if err := i.inject(run...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// These are the devices that inputDevice returns.
+const (
+	mouseAndKeyboard = iota
+	touchDevice
+	penDevice
+)
+
+// inputDevice returns the device that generates the given input.
+func inputDevice(e InputEvent) int {
+	if isTouch(e) {
+		return touchDevice
+	}
+	if e.Type == InputPen {
+		return penDevice
+	}
+	return mouseAndKeyboard
+}
+
+func (linuxDriver) MousePosition() (x, y int, err error) {
+	i, err := currentInjector()
+	if err != nil {
+		return 0, 0, err
+	}
+	return i.mousePosition()
+}
+
+func (linuxDriver) Type(s string, options TypeOptions) error {
+	if options.Mode == TypeAltNumpad {
+		return errors.New("typing with Alt+Numpad only works on Windows")
+	}
+	i, err := currentInjector()
+	if err != nil {
+		return err
+	}
+	return i.typeText(s, options)
+}
+
+// xMousePosition returns the position of the X server's pointer.
+func xMousePosition() (x, y int, err error) {
+	c, err := display()
+	if err != nil {
+		return 0, 0, err
+	}
+	const queryPointer = 38
+	reply, err := c.roundTrip(newXRequest(queryPointer, 0).u32(c.screen.root))
+	if err != nil {
+		return 0, 0, err
+	}
+	x = int(int16(le.Uint16(reply[16:])))
+	y = int(int16(le.Uint16(reply[18:])))
+	return x, y, nil
+}
+
+// wheelRemainder accumulates fractions of mouse wheel ticks. X11 only knows
+// whole ticks (presses of buttons 4 to 7) so we send a tick once the fractions
+// add up to one.
+var wheelRemainder struct {
+	sync.Mutex
+	dx, dy float64
+}
+
+// injector generates mouse and keyboard input. The X11 injector uses the
+// XTEST extension. Without an X server, e.g. on Wayland or on the Linux
+// console, the uinput injector creates virtual input devices in the kernel
+// instead.
+type injector interface {
+	inject(events ...InputEvent) error
+	mousePosition() (x, y int, err error)
+	typeText(s string, options TypeOptions) error
+	keysForRune(r rune) ([]InputEvent, error)
+}
+
+var injectors struct {
+	sync.Mutex
+	current injector
+}
+
+// currentInjector returns the injector for this session. Wayland sessions and
+// sessions without an X server use uinput. If uinput is not available on
+// Wayland, we fall back to XWayland.
+func currentInjector() (injector, error) {
+	injectors.Lock()
+	defer injectors.Unlock()
+
+	if injectors.current != nil {
+		return injectors.current, nil
+	}
+
+	if os.Getenv("WAYLAND_DISPLAY") != "" || os.Getenv("DISPLAY") == "" {
+		u, err := newUinputInjector()
+		if err == nil {
+			injectors.current = u
+			return u, nil
+		}
+		if os.Getenv("DISPLAY") == "" {
+			return nil, err
+		}
+	}
+
+	injectors.current = xtestInjector{}
+	return injectors.current, nil
+}
+
+// xtestInjector generates input with the XTEST extension.
+type xtestInjector struct{}
+
+func (xtestInjector) inject(events ...InputEvent) error {
+	fake := make([]xFakeEvent, 0, len(events))
+	var released []uint16
+	for _, e := range events {
+		switch e.Type {
+		case InputKeyDown, InputKeyUp:
+			f, err := keyEvent(e.Key, e.Type == InputKeyDown)
+			if err != nil {
+				return err
+			}
+			fake = append(fake, f)
+			if e.Type == InputKeyUp {
+				released = append(released, e.Key)
+			}
+		case InputButtonDown, InputButtonUp:
+			button, ok := xButtons[e.Button]
+			if !ok {
+				return fmt.Errorf("unknown mouse button %d", e.Button)
+			}
+			typ := byte(xButtonPress)
+			if e.Type == InputButtonUp {
+				typ = xButtonRelease
+			}
+			fake = append(fake, xFakeEvent{typ: typ, detail: button})
+		case InputMoveTo, InputMoveBy:
+			x, y := e.X, e.Y
+			if e.Type == InputMoveBy {
+				// Relative XTEST motion might be subject to pointer
+				// acceleration, so we move to the absolute position instead.
+				if len(fake) > 0 {
+					if err := fakeInput(fake...); err != nil {
+						return err
+					}
+					fake = fake[:0]
+				}
+				curX, curY, err := xMousePosition()
+				if err != nil {
+					return err
+				}
+				x, y = curX+x, curY+y
+			}
+			fake = append(fake, xFakeEvent{typ: xMotionNotify, x: x, y: y})
+		case InputMoveRaw:
+			const relative = 1
+			fake = append(fake, xFakeEvent{typ: xMotionNotify, detail: relative, x: e.X, y: e.Y})
+		case InputWheel:
+			fake = append(fake, wheelTicks(e.WheelX, e.WheelY)...)
+		}
+	}
+	if len(fake) == 0 {
+		return nil
+	}
+	if err := fakeInput(fake...); err != nil {
+		return err
+	}
+	return unmapSpareKeys(released)
+}
+
+func (xtestInjector) typeText(s string, options TypeOptions) error {
+	c, err := display()
+	if err != nil {
+		return err
+	}
+	mapping, err := c.keyboardMapping()
+	if err != nil {
+		return err
+	}
+	shift, _, ok := mapping.find(xkShiftL)
+	if !ok {
+		return errors.New("the keyboard layout has no Shift key")
+	}
+
+	// Characters that are not on the keyboard layout are mapped to spare key
+	// codes, each character to its own code as long as there are enough. We
+	// restore these once we are done typing.
+	spare := mapping.spareKeycodes()
+	nextSpare := 0
+	spareCodes := make(map[uint32]byte) // Key symbol to key code.
+	spareSyms := make(map[byte]uint32)  // Key code to key symbol.
+	defer func() {
+		if len(spareSyms) > 0 {
+			waitForClients(c)
+			for code := range spareSyms {
+				c.changeKeyboardMapping(code, 0, mapping.perKeycode)
+			}
+		}
+	}()
+
+	for _, r := range s {
+		var sym uint32
+		switch r {
+		case '\r':
+			sym = xkReturn
+		case '\b':
+			sym = xkBackSpace
+		case '\t':
+			sym = xkTab
+		default:
+			sym = runeToKeySym(r)
+		}
+
+		code, column, ok := mapping.findRune(sym)
+		if !ok {
+			column = 0
+			code, ok = spareCodes[sym]
+		}
+		if !ok {
+			if len(spare) == 0 {
+				return fmt.Errorf("cannot type %q, it is not on the keyboard layout and there is no spare key code to map it to", r)
+			}
+			code = spare[nextSpare]
+			nextSpare = (nextSpare + 1) % len(spare)
+			if previous, ok := spareSyms[code]; ok {
+				// We ran out of spare key codes and reuse one that an earlier
+				// character was typed with.
+				delete(spareCodes, previous)
+				if err := waitForClients(c); err != nil {
+					return err
+				}
+			}
+			if err := c.changeKeyboardMapping(code, sym, mapping.perKeycode); err != nil {
+				return err
+			}
+			spareCodes[sym] = code
+			spareSyms[code] = sym
+		}
+
+		var events []xFakeEvent
+		if column == 1 {
+			events = append(events, xFakeEvent{typ: xKeyPress, detail: shift})
+		}
+		events = append(events,
+			xFakeEvent{typ: xKeyPress, detail: code},
+			xFakeEvent{typ: xKeyRelease, detail: code},
+		)
+		if column == 1 {
+			events = append(events, xFakeEvent{typ: xKeyRelease, detail: shift})
+		}
+		if err := fakeInput(events...); err != nil {
+			return err
+		}
+
+		time.Sleep(options.Delay)
+	}
+	return nil
+}
+
+func (xtestInjector) mousePosition() (x, y int, err error) {
+	return xMousePosition()
+}
+
+// wheelTicks returns the button presses for rotating the mouse wheel. X11 only
+// knows whole ticks, see wheelRemainder.
+func wheelTicks(dx, dy float64) []xFakeEvent {
+	wheelRemainder.Lock()
+	wheelRemainder.dx += dx
+	wheelRemainder.dy += dy
+	ticksX := int(wheelRemainder.dx)
+	ticksY := int(wheelRemainder.dy)
+	wheelRemainder.dx -= float64(ticksX)
+	wheelRemainder.dy -= float64(ticksY)
+	wheelRemainder.Unlock()
+
+	var events []xFakeEvent
+	tick := func(button byte) {
+		events = append(events,
+			xFakeEvent{typ: xButtonPress, detail: button},
+			xFakeEvent{typ: xButtonRelease, detail: button},
+		)
+	}
+	for ; ticksY > 0; ticksY-- {
+		tick(xWheelUp)
+	}
+	for ; ticksY < 0; ticksY++ {
+		tick(xWheelDown)
+	}
+	for ; ticksX > 0; ticksX-- {
+		tick(xWheelRight)
+	}
+	for ; ticksX < 0; ticksX++ {
+		tick(xWheelLeft)
+	}
+
+	return events
+}
+
+// waitForClients is called before changing the mapping of a key code that we
+// generated key events for. The server has to process these events and the
+// clients have to handle them before the mapping changes under them.
+func waitForClients(c *xConn) error {
+	if err := c.sync(); err != nil {
+		return err
+	}
+	time.Sleep(50 * time.Millisecond)
+	return nil
+}
+
+// spareKeys are the keys that keyEvent mapped to spare key codes, because they
+// are not on the keyboard layout. The key codes are unmapped again once the
+// keys are released.
+var spareKeys = struct {
+	sync.Mutex
+	codes map[uint16]byte
+}{codes: make(map[uint16]byte)}
+
+// keyEvent returns the XTEST event for pressing or releasing the given key.
+// The mouse button keys, e.g. KeyLeftButton, press the mouse buttons. If the
+// key is not on the current keyboard layout, it is mapped to a spare key code
+// which stays mapped until the key is released, see unmapSpareKeys.
+func keyEvent(key uint16, down bool) (xFakeEvent, error) {
+	if button, ok := keyButtons[key]; ok {
+		if down {
+			return xFakeEvent{typ: xButtonPress, detail: button}, nil
+		}
+		return xFakeEvent{typ: xButtonRelease, detail: button}, nil
+	}
+
+	sym, ok := keySyms[key]
+	if !ok {
+		return xFakeEvent{}, fmt.Errorf("key %d has no X11 equivalent", key)
+	}
+
+	c, err := display()
+	if err != nil {
+		return xFakeEvent{}, err
+	}
+	mapping, err := c.keyboardMapping()
+	if err != nil {
+		return xFakeEvent{}, err
+	}
+	code, _, ok := mapping.find(sym)
+	if !ok && key == KeyRightAlt {
+		// Many layouts use the right Alt key as AltGr.
+		code, _, ok = mapping.find(xkISOLevel3Shift)
+	}
+	if !ok {
+		spare := mapping.spareKeycodes()
+		if len(spare) == 0 {
+			return xFakeEvent{}, fmt.Errorf("key %d is not on the keyboard layout and there is no spare key code to map it to", key)
+		}
+		code = spare[0]
+		if err := c.changeKeyboardMapping(code, sym, mapping.perKeycode); err != nil {
+			return xFakeEvent{}, err
+		}
+		spareKeys.Lock()
+		spareKeys.codes[key] = code
+		spareKeys.Unlock()
+	}
+
+	if down {
+		return xFakeEvent{typ: xKeyPress, detail: code}, nil
+	}
+	return xFakeEvent{typ: xKeyRelease, detail: code}, nil
+}
+
+// unmapSpareKeys unmaps the spare key codes that keyEvent mapped for the given
+// keys, which were just released.
+func unmapSpareKeys(keys []uint16) error {
+	spareKeys.Lock()
+	defer spareKeys.Unlock()
+
+	var codes []byte
+	for _, key := range keys {
+		if code, ok := spareKeys.codes[key]; ok {
+			codes = append(codes, code)
+			delete(spareKeys.codes, key)
+		}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+
+	c, err := display()
+	if err != nil {
+		return err
+	}
+	mapping, err := c.keyboardMapping()
+	if err != nil {
+		return err
+	}
+	if err := waitForClients(c); err != nil {
+		return err
+	}
+	for _, code := range codes {
+		if err := c.changeKeyboardMapping(code, 0, mapping.perKeycode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// xKeyboardMapping is the server's mapping of key codes to key symbols. Every
+// key code has perKeycode symbols, the first is the unshifted symbol, the
+// second is the symbol with Shift held down.
+type xKeyboardMapping struct {
+	minKeycode byte
+	perKeycode int
+	keySyms    []uint32
+}
+
+func (c *xConn) keyboardMapping() (xKeyboardMapping, error) {
+	const getKeyboardMapping = 101
+	count := int(c.maxKeycode) - int(c.minKeycode) + 1
+	reply, err := c.roundTrip(
+		newXRequest(getKeyboardMapping, 0).
+			u8(c.minKeycode).
+			u8(byte(count)).
+			pad(2),
+	)
+	if err != nil {
+		return xKeyboardMapping{}, err
+	}
+	m := xKeyboardMapping{
+		minKeycode: c.minKeycode,
+		perKeycode: int(reply[1]),
+		keySyms:    make([]uint32, (len(reply)-32)/4),
+	}
+	for i := range m.keySyms {
+		m.keySyms[i] = le.Uint32(reply[32+4*i:])
+	}
+	return m, nil
+}
+
+// keySym returns the unshifted key symbol of the given key code, or 0 if the
+// key code is not mapped.
+func (m xKeyboardMapping) keySym(code byte) uint32 {
+	i := (int(code) - int(m.minKeycode)) * m.perKeycode
+	if code < m.minKeycode || i >= len(m.keySyms) {
+		return 0
+	}
+	return m.keySyms[i]
+}
+
+// find returns the key code that produces the given key symbol. column is 0 if
+// the key produces the symbol without modifiers and 1 if Shift needs to be
+// held down.
+func (m xKeyboardMapping) find(sym uint32) (code byte, column int, ok bool) {
+	for column := 0; column < 2 && column < m.perKeycode; column++ {
+		for i := column; i < len(m.keySyms); i += m.perKeycode {
+			if m.keySyms[i] == sym {
+				return m.minKeycode + byte(i/m.perKeycode), column, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// findRune is like find but compares the characters that key symbols
+// represent. This finds characters that the layout maps to legacy key symbols.
+// It also handles keys that only list a lower case letter, in which case Shift
+// produces the upper case letter.
+func (m xKeyboardMapping) findRune(sym uint32) (code byte, column int, ok bool) {
+	if code, column, ok := m.find(sym); ok {
+		return code, column, ok
+	}
+	r := keySymToRune(sym)
+	if r == -1 {
+		return 0, 0, false
+	}
+	lower := unicode.ToLower(r)
+	for i := 0; i+1 < len(m.keySyms) && m.perKeycode >= 2; i += m.perKeycode {
+		code := m.minKeycode + byte(i/m.perKeycode)
+		if keySymToRune(m.keySyms[i]) == r {
+			return code, 0, true
+		}
+		if keySymToRune(m.keySyms[i+1]) == r {
+			return code, 1, true
+		}
+		if lower != r && m.keySyms[i+1] == 0 && keySymToRune(m.keySyms[i]) == lower {
+			return code, 1, true
+		}
+	}
+	return 0, 0, false
+}
+
+// spareKeycodes returns all key codes that have no key symbols mapped to them.
+func (m xKeyboardMapping) spareKeycodes() []byte {
+	var spare []byte
+	for i := 0; i < len(m.keySyms); i += m.perKeycode {
+		empty := true
+		for _, sym := range m.keySyms[i : i+m.perKeycode] {
+			if sym != 0 {
+				empty = false
+			}
+		}
+		if empty {
+			spare = append(spare, m.minKeycode+byte(i/m.perKeycode))
+		}
+	}
+	return spare
+}
+
+// changeKeyboardMapping maps the given key code to the key symbol, with and
+// without Shift. Pass key symbol 0 to unmap the key code.
+func (c *xConn) changeKeyboardMapping(code byte, sym uint32, perKeycode int) error {
+	const changeKeyboardMapping = 100
+	r := newXRequest(changeKeyboardMapping, 1).
+		u8(code).
+		u8(byte(perKeycode)).
+		pad(2)
+	for i := 0; i < perKeycode; i++ {
+		if i < 2 {
+			r = r.u32(sym)
+		} else {
+			r = r.u32(0)
+		}
+	}
+	return c.exec(r)
+}
+
+// These are the core X11 event types that the XTEST extension can fake.
+const (
+	xKeyPress      = 2
+	xKeyRelease    = 3
+	xButtonPress   = 4
+	xButtonRelease = 5
+	xMotionNotify  = 6
+)
+
+// xFakeEvent is an input event to be generated with the XTEST extension.
+// Detail is the key code for key events, the button number for button events
+// and 0 for absolute motion events. X and Y are only used for motion events.
+type xFakeEvent struct {
+	typ    byte
+	detail byte
+	x, y   int
+}
+
+// fakeInput generates the given input events with the XTEST extension and
+// waits until the X server has processed them.
+func fakeInput(events ...xFakeEvent) error {
+	c, err := display()
+	if err != nil {
+		return err
+	}
+	xtest, err := c.extension("XTEST")
+	if err != nil {
+		return err
+	}
+
+	const fakeInput = 2
+	requests := make([]xRequest, len(events))
+	for i, e := range events {
+		requests[i] = newXRequest(xtest.opcode, fakeInput).
+			u8(e.typ).
+			u8(e.detail).
+			pad(2).
+			u32(0). // CurrentTime
+			u32(c.screen.root).
+			pad(8).
+			i16(e.x).
+			i16(e.y).
+			pad(7).
+			u8(0) // Device ID, 0 means the core devices.
+	}
+	return c.exec(requests...)
+}
+
+// Monitors uses RandR and the window manager's work area.
+func (linuxDriver) Monitors() ([]Monitor, error) {
+	c, err := display()
+	if err != nil {
+		return nil, err
+	}
+
+	monitors, err := c.randrMonitors()
+	if err != nil || len(monitors) == 0 {
+		// Without RandR 1.5 we treat the whole screen as one monitor.
+		monitors = []Monitor{{
+			Rectangle: Rectangle{
+				Width:  c.screen.width,
+				Height: c.screen.height,
+			},
+			Primary: true,
+		}}
+	}
+
+	primary := false
+	for _, m := range monitors {
+		primary = primary || m.Primary
+	}
+	if !primary {
+		monitors[0].Primary = true
+	}
+
+	workArea, haveWorkArea := c.workArea()
+	for i := range monitors {
+		monitors[i].WorkArea = monitors[i].Rectangle
+		if haveWorkArea {
+			monitors[i].WorkArea = intersect(monitors[i].Rectangle, workArea)
+		}
+	}
+
+	return monitors, nil
+}
+
+// randrMonitors queries the monitors with the RandR extension, version 1.5 or
+// higher.
+func (c *xConn) randrMonitors() ([]Monitor, error) {
+	randr, err := c.extension("RANDR")
+	if err != nil {
+		return nil, err
+	}
+
+	const (
+		queryVersion = 0
+		getMonitors  = 42
+	)
+	version, err := c.roundTrip(newXRequest(randr.opcode, queryVersion).u32(1).u32(5))
+	if err != nil {
+		return nil, err
+	}
+	major, minor := le.Uint32(version[8:]), le.Uint32(version[12:])
+	if major < 1 || major == 1 && minor < 5 {
+		return nil, errors.New("RandR 1.5 is not supported by the X server")
+	}
+
+	reply, err := c.roundTrip(
+		newXRequest(randr.opcode, getMonitors).
+			u32(c.screen.root).
+			u8(1). // Only active monitors.
+			pad(3),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	count := int(le.Uint32(reply[12:]))
+	monitors := make([]Monitor, count)
+	info := reply[32:]
+	for i := range monitors {
+		monitors[i] = Monitor{
+			Primary: info[4] != 0,
+			Rectangle: Rectangle{
+				X:      int(int16(le.Uint16(info[8:]))),
+				Y:      int(int16(le.Uint16(info[10:]))),
+				Width:  int(le.Uint16(info[12:])),
+				Height: int(le.Uint16(info[14:])),
+			},
+		}
+		outputCount := int(le.Uint16(info[6:]))
+		info = info[24+4*outputCount:]
+	}
+	return monitors, nil
+}
+
+// workArea returns the work area of the current desktop as the window manager
+// reports it in _NET_WORKAREA. This is the area of the virtual screen that is
+// not covered by panels and docks.
+func (c *xConn) workArea() (Rectangle, bool) {
+	workAreaAtom, err := c.atom("_NET_WORKAREA")
+	if err != nil {
+		return Rectangle{}, false
+	}
+	prop, err := c.getProperty(c.screen.root, workAreaAtom, xAtomCardinal, false)
+	if err != nil {
+		return Rectangle{}, false
+	}
+	areas := prop.uint32s()
+
+	desktop := 0
+	if currentAtom, err := c.atom("_NET_CURRENT_DESKTOP"); err == nil {
+		current, err := c.getProperty(c.screen.root, currentAtom, xAtomCardinal, false)
+		if values := current.uint32s(); err == nil && len(values) == 1 {
+			desktop = int(values[0])
+		}
+	}
+
+	if len(areas) < 4*(desktop+1) {
+		return Rectangle{}, false
+	}
+	a := areas[4*desktop:]
+	return Rectangle{
+		X:      int(int32(a[0])),
+		Y:      int(int32(a[1])),
+		Width:  int(a[2]),
+		Height: int(a[3]),
+	}, true
+}
+
+func intersect(a, b Rectangle) Rectangle {
+	left, top := a.X, a.Y
+	right, bottom := a.X+a.Width, a.Y+a.Height
+	if b.X > left {
+		left = b.X
+	}
+	if b.Y > top {
+		top = b.Y
+	}
+	if b.X+b.Width < right {
+		right = b.X + b.Width
+	}
+	if b.Y+b.Height < bottom {
+		bottom = b.Y + b.Height
+	}
+	if right < left {
+		right = left
+	}
+	if bottom < top {
+		bottom = top
+	}
+	return Rectangle{X: left, Y: top, Width: right - left, Height: bottom - top}
+}
+
+// CaptureScreen leaves parts of the area that lie outside the root window
+// transparent.
+func (linuxDriver) CaptureScreen(x, y, width, height int) (image.Image, error) {
+	img := image.NewRGBA(image.Rect(x, y, x+width, y+height))
+
+	c, err := display()
+	if err != nil {
+		return nil, err
+	}
+
+	// The X server only gives us pixels inside the root window.
+	r := intersect(
+		Rectangle{X: x, Y: y, Width: width, Height: height},
+		Rectangle{Width: c.screen.width, Height: c.screen.height},
+	)
+	if r.Width == 0 || r.Height == 0 {
+		return img, nil
+	}
+
+	depth, data, err := c.getImageShm(r)
+	if err != nil {
+		depth, data, err = c.getImage(r)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bitsPerPixel := 0
+	for _, f := range c.formats {
+		if f.depth == depth {
+			bitsPerPixel = int(f.bitsPerPixel)
+		}
+	}
+	if bitsPerPixel != 32 {
+		return nil, fmt.Errorf(
+			"capturing the screen is only supported for 32 bits per pixel, the screen uses %d",
+			bitsPerPixel,
+		)
+	}
+
+	// The X server gives us BGRX pixels, we want RGBA.
+	stride := 4 * r.Width
+	for row := 0; row < r.Height; row++ {
+		src := data[row*stride : (row+1)*stride]
+		dest := img.Pix[img.PixOffset(r.X, r.Y+row):]
+		for i := 0; i < len(src); i += 4 {
+			dest[i+0] = src[i+2]
+			dest[i+1] = src[i+1]
+			dest[i+2] = src[i+0]
+			dest[i+3] = 255
+		}
+	}
+
+	return img, nil
+}
+
+// xImageFormatZPixmap is the image format that stores pixels in scanline order.
+const xImageFormatZPixmap = 2
+
+// getImage reads the pixels in the given area of the root window with the core
+// GetImage request, which transfers them through the X connection.
+func (c *xConn) getImage(r Rectangle) (depth byte, data []byte, err error) {
+	const getImage = 73
+	reply, err := c.roundTrip(
+		newXRequest(getImage, xImageFormatZPixmap).
+			u32(c.screen.root).
+			i16(r.X).
+			i16(r.Y).
+			u16(uint16(r.Width)).
+			u16(uint16(r.Height)).
+			u32(0xFFFFFFFF), // Plane mask.
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	return reply[1], reply[32:], nil
+}
+
+// getImageShm reads the pixels in the given area of the root window through
+// shared memory with the MIT-SHM extension. This is a lot faster than getImage
+// but only works if the X server runs on the local machine.
+func (c *xConn) getImageShm(r Rectangle) (depth byte, data []byte, err error) {
+	shm, err := c.extension("MIT-SHM")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	const (
+		queryVersion = 0
+		detach       = 2
+		getImage     = 4
+		attachFd     = 6
+	)
+
+	// We need version 1.2 to pass a file descriptor for the shared memory.
+	version, err := c.roundTrip(newXRequest(shm.opcode, queryVersion))
+	if err != nil {
+		return 0, nil, err
+	}
+	major, minor := le.Uint16(version[8:]), le.Uint16(version[10:])
+	if major < 1 || major == 1 && minor < 2 {
+		return 0, nil, errors.New("MIT-SHM 1.2 is not supported by the X server")
+	}
+
+	size := 4 * r.Width * r.Height
+	file, err := os.CreateTemp("/dev/shm", "auto-capture-")
+	if err != nil {
+		file, err = os.CreateTemp("", "auto-capture-")
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	os.Remove(file.Name())
+	defer file.Close()
+	if err := file.Truncate(int64(size)); err != nil {
+		return 0, nil, err
+	}
+	memory, err := syscall.Mmap(
+		int(file.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer syscall.Munmap(memory)
+
+	segment, err := c.newID()
+	if err != nil {
+		return 0, nil, err
+	}
+	attach, err := c.sendWithFile(
+		newXRequest(shm.opcode, attachFd).
+			u32(segment).
+			u8(0). // The server may write to the memory.
+			pad(3),
+		true,
+		file,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer c.forget(attach)
+	defer c.post(newXRequest(shm.opcode, detach).u32(segment))
+
+	reply, err := c.roundTrip(
+		newXRequest(shm.opcode, getImage).
+			u32(c.screen.root).
+			i16(r.X).
+			i16(r.Y).
+			u16(uint16(r.Width)).
+			u16(uint16(r.Height)).
+			u32(0xFFFFFFFF). // Plane mask.
+			u8(xImageFormatZPixmap).
+			pad(3).
+			u32(segment).
+			u32(0), // Offset into the shared memory.
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	if int(le.Uint32(reply[12:])) < size {
+		return 0, nil, errors.New("MIT-SHM returned too little image data")
+	}
+
+	data = make([]byte, size)
+	copy(data, memory)
+	return reply[1], data, nil
+}
+
+// Windows prefers the window manager's list of client windows.
+func (linuxDriver) Windows() ([]Window, error) {
+	c, err := display()
+	if err != nil {
+		return nil, err
+	}
+
+	// Prefer the window manager's list of client windows. Without a window
+	// manager we fall back to the top-level windows.
+	var handles []uint32
+	if clientList, err := c.atom("_NET_CLIENT_LIST"); err == nil {
+		prop, err := c.getProperty(c.screen.root, clientList, xAtomWindow, false)
+		if err == nil {
+			handles = prop.uint32s()
+		}
+	}
+	if handles == nil {
+		const queryTree = 15
+		reply, err := c.roundTrip(newXRequest(queryTree, 0).u32(c.screen.root))
+		if err != nil {
+			return nil, err
+		}
+		handles = make([]uint32, le.Uint16(reply[16:]))
+		for i := range handles {
+			handles[i] = le.Uint32(reply[32+4*i:])
+		}
+	}
+
+	windows := make([]Window, len(handles))
+	for i := range windows {
+		windows[i] = windowHandleToWindow(handles[i])
+	}
+	return windows, nil
+}
+
+func (linuxDriver) ForegroundWindow() (Window, error) {
+	c, err := display()
+	if err != nil {
+		return Window{}, err
+	}
+
+	var active uint32
+	if activeAtom, err := c.atom("_NET_ACTIVE_WINDOW"); err == nil {
+		prop, err := c.getProperty(c.screen.root, activeAtom, xAtomWindow, false)
+		if values := prop.uint32s(); err == nil && len(values) == 1 {
+			active = values[0]
+		}
+	}
+	if active == 0 {
+		// Without a window manager, the active window is the one with the
+		// keyboard focus.
+		const getInputFocus = 43
+		reply, err := c.roundTrip(newXRequest(getInputFocus, 0))
+		if err != nil {
+			return Window{}, err
+		}
+		const pointerRoot = 1
+		active = le.Uint32(reply[8:])
+		if active == pointerRoot || active == c.screen.root {
+			active = 0
+		}
+	}
+	if active == 0 {
+		return Window{}, errors.New("no window is active")
+	}
+	return windowHandleToWindow(active), nil
+}
+
+func (linuxDriver) UpdateWindow(w Window) Window {
+	return windowHandleToWindow(uint32(w.Handle))
+}
+
+func (linuxDriver) BringWindowToForeground(w Window) error {
+	c, err := display()
+	if err != nil {
+		return err
+	}
+	if c.windowManagerSupports("_NET_ACTIVE_WINDOW") {
+		const sourcePager = 2
+		err = c.sendClientMessage(uint32(w.Handle), "_NET_ACTIVE_WINDOW", sourcePager)
+	} else {
+		// Without a window manager we raise and focus the window ourselves.
+		const (
+			mapWindow       = 8
+			configureWindow = 12
+			setInputFocus   = 42
+			stackMode       = 0x40
+			above           = 0
+			revertToParent  = 2
+		)
+		err = c.exec(
+			newXRequest(mapWindow, 0).u32(uint32(w.Handle)),
+			newXRequest(configureWindow, 0).u32(uint32(w.Handle)).u16(stackMode).pad(2).u32(above),
+			newXRequest(setInputFocus, revertToParent).u32(uint32(w.Handle)).u32(0),
+		)
+	}
+	return err
+}
+
+func (d linuxDriver) RestoreWindow(w Window) {
+	if c, err := display(); err == nil {
+		if w.Minimized {
+			d.BringWindowToForeground(w)
+		}
+		c.setMaximized(uint32(w.Handle), false)
+	}
+}
+
+func (d linuxDriver) MaximizeWindow(w Window) {
+	if c, err := display(); err == nil {
+		if w.Minimized {
+			d.BringWindowToForeground(w)
+		}
+		c.setMaximized(uint32(w.Handle), true)
+	}
+}
+
+func (linuxDriver) MinimizeWindow(w Window) {
+	if c, err := display(); err == nil {
+		const iconicState = 3
+		c.sendClientMessage(uint32(w.Handle), "WM_CHANGE_STATE", iconicState)
+	}
+}
+
+func (linuxDriver) HideWindow(w Window) {
+	if c, err := display(); err == nil {
+		// This is what ICCCM calls withdrawing a window, we unmap it and tell
+		// the window manager about it with a synthetic UnmapNotify event.
+		const (
+			unmapWindow                = 10
+			unmapNotify                = 18
+			substructureNotifyMask     = 1 << 19
+			substructureRedirectMask   = 1 << 20
+			substructureRedirectNotify = substructureNotifyMask | substructureRedirectMask
+		)
+		event := make([]byte, 32)
+		event[0] = unmapNotify
+		le.PutUint32(event[4:], c.screen.root)
+		le.PutUint32(event[8:], uint32(w.Handle))
+		c.exec(
+			newXRequest(unmapWindow, 0).u32(uint32(w.Handle)),
+			c.sendEvent(c.screen.root, substructureRedirectNotify, event),
+		)
+	}
+}
+
+func (linuxDriver) ShowWindow(w Window) {
+	if c, err := display(); err == nil {
+		const mapWindow = 8
+		c.exec(newXRequest(mapWindow, 0).u32(uint32(w.Handle)))
+	}
+}
+
+func (linuxDriver) WindowInnerPosition(w Window) (x, y, width, height int, err error) {
+	c, err := display()
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	r, err := c.windowContent(uint32(w.Handle))
+	return r.X, r.Y, r.Width, r.Height, err
+}
+
+func (linuxDriver) SetWindowInnerPosition(w Window, x, y, width, height int) error {
+	c, err := display()
+	if err != nil {
+		return err
+	}
+	return c.moveResizeWindow(uint32(w.Handle), x, y, width, height)
+}
+
+// WindowOuterPosition adds the frame extents that the window manager reports
+// to the window content.
+func (linuxDriver) WindowOuterPosition(w Window) (x, y, width, height int, err error) {
+	c, err := display()
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	content, err := c.windowContent(uint32(w.Handle))
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	left, right, top, bottom := c.frameExtents(uint32(w.Handle))
+	return content.X - left,
+		content.Y - top,
+		content.Width + left + right,
+		content.Height + top + bottom,
+		nil
+}
+
+func (linuxDriver) SetWindowOuterPosition(w Window, x, y, width, height int) error {
+	c, err := display()
+	if err != nil {
+		return err
+	}
+	// The window manager positions the window content, so we subtract the
+	// window frame ourselves.
+	left, right, top, bottom := c.frameExtents(uint32(w.Handle))
+	return c.moveResizeWindow(
+		uint32(w.Handle),
+		x+left,
+		y+top,
+		width-left-right,
+		height-top-bottom,
+	)
+}
+
+func windowHandleToWindow(window uint32) Window {
+	w := Window{Handle: uintptr(window)}
+
+	c, err := display()
+	if err != nil {
+		return w
+	}
+
+	w.Title = c.windowTitle(window)
+
+	if class, err := c.getProperty(window, xAtomWMClass, xAtomString, false); err == nil {
+		// WM_CLASS holds two null-terminated strings, the instance name and
+		// the class name.
+		parts := strings.Split(string(class.data), "\x00")
+		if len(parts) >= 2 {
+			w.ClassName = parts[1]
+		}
+	}
+
+	if stateAtom, err := c.atom("_NET_WM_STATE"); err == nil {
+		state, _ := c.getProperty(window, stateAtom, xAtomAtom, false)
+		var maxVert, maxHorz bool
+		for _, a := range state.uint32s() {
+			switch name, _ := c.atomName(a); name {
+			case "_NET_WM_STATE_MAXIMIZED_VERT":
+				maxVert = true
+			case "_NET_WM_STATE_MAXIMIZED_HORZ":
+				maxHorz = true
+			case "_NET_WM_STATE_HIDDEN":
+				w.Minimized = true
+			}
+		}
+		w.Maximized = maxVert && maxHorz
+	}
+	if wmState, err := c.atom("WM_STATE"); err == nil {
+		state, _ := c.getProperty(window, wmState, wmState, false)
+		const iconicState = 3
+		if values := state.uint32s(); len(values) > 0 && values[0] == iconicState {
+			w.Minimized = true
+		}
+	}
+
+	const getWindowAttributes = 3
+	attributes, err := c.roundTrip(newXRequest(getWindowAttributes, 0).u32(window))
+	if err == nil {
+		const viewable = 2
+		w.Visible = attributes[26] == viewable || w.Minimized
+	}
+
+	if content, err := c.windowContent(window); err == nil {
+		w.Content = content
+		left, right, top, bottom := c.frameExtents(window)
+		w.Rectangle = Rectangle{
+			X:      content.X - left,
+			Y:      content.Y - top,
+			Width:  content.Width + left + right,
+			Height: content.Height + top + bottom,
+		}
+	}
+
+	return w
+}
+
+// windowTitle returns the window's _NET_WM_NAME or, if it does not have one,
+// its WM_NAME.
+func (c *xConn) windowTitle(window uint32) string {
+	if netName, err := c.atom("_NET_WM_NAME"); err == nil {
+		if utf8, err := c.atom("UTF8_STRING"); err == nil {
+			name, err := c.getProperty(window, netName, utf8, false)
+			if err == nil && len(name.data) > 0 {
+				return string(name.data)
+			}
+		}
+	}
+	name, err := c.getProperty(window, xAtomWMName, 0, false)
+	if err != nil {
+		return ""
+	}
+	return string(name.data)
+}
+
+// windowContent returns the window's inner boundaries in screen coordinates.
+func (c *xConn) windowContent(window uint32) (Rectangle, error) {
+	const (
+		getGeometry          = 14
+		translateCoordinates = 40
+	)
+	geometry, err := c.roundTrip(newXRequest(getGeometry, 0).u32(window))
+	if err != nil {
+		return Rectangle{}, err
+	}
+	origin, err := c.roundTrip(
+		newXRequest(translateCoordinates, 0).
+			u32(window).
+			u32(c.screen.root).
+			i16(0).
+			i16(0),
+	)
+	if err != nil {
+		return Rectangle{}, err
+	}
+	return Rectangle{
+		X:      int(int16(le.Uint16(origin[12:]))),
+		Y:      int(int16(le.Uint16(origin[14:]))),
+		Width:  int(le.Uint16(geometry[16:])),
+		Height: int(le.Uint16(geometry[18:])),
+	}, nil
+}
+
+// frameExtents returns the size of the border that the window manager draws
+// around the window, as given in _NET_FRAME_EXTENTS. Without a window manager
+// all extents are 0.
+func (c *xConn) frameExtents(window uint32) (left, right, top, bottom int) {
+	extentsAtom, err := c.atom("_NET_FRAME_EXTENTS")
+	if err != nil {
+		return
+	}
+	prop, err := c.getProperty(window, extentsAtom, xAtomCardinal, false)
+	if extents := prop.uint32s(); err == nil && len(extents) == 4 {
+		left = int(extents[0])
+		right = int(extents[1])
+		top = int(extents[2])
+		bottom = int(extents[3])
+	}
+	return
+}
+
+// windowManagerSupports checks whether the EWMH hint of the given name is
+// listed in the window manager's _NET_SUPPORTED.
+func (c *xConn) windowManagerSupports(hint string) bool {
+	supportedAtom, err := c.atom("_NET_SUPPORTED")
+	if err != nil {
+		return false
+	}
+	hintAtom, err := c.atom(hint)
+	if err != nil {
+		return false
+	}
+	supported, err := c.getProperty(c.screen.root, supportedAtom, xAtomAtom, false)
+	if err != nil {
+		return false
+	}
+	for _, a := range supported.uint32s() {
+		if a == hintAtom {
+			return true
+		}
+	}
+	return false
+}
+
+// setMaximized asks the window manager to add or remove the maximized state
+// of the window.
+func (c *xConn) setMaximized(window uint32, maximized bool) error {
+	vert, err := c.atom("_NET_WM_STATE_MAXIMIZED_VERT")
+	if err != nil {
+		return err
+	}
+	horz, err := c.atom("_NET_WM_STATE_MAXIMIZED_HORZ")
+	if err != nil {
+		return err
+	}
+	const (
+		remove      = 0
+		add         = 1
+		sourcePager = 2
+	)
+	action := uint32(remove)
+	if maximized {
+		action = add
+	}
+	return c.sendClientMessage(window, "_NET_WM_STATE", action, vert, horz, sourcePager)
+}
+
+// moveResizeWindow places the window content at the given screen coordinates.
+func (c *xConn) moveResizeWindow(window uint32, x, y, width, height int) error {
+	if c.windowManagerSupports("_NET_MOVERESIZE_WINDOW") {
+		// Static gravity means that x and y are the position of the window
+		// content, not of the window manager's frame.
+		const (
+			staticGravity = 10
+			xywhPresent   = 0xF << 8
+			sourcePager   = 2 << 12
+		)
+		return c.sendClientMessage(
+			window,
+			"_NET_MOVERESIZE_WINDOW",
+			staticGravity|xywhPresent|sourcePager,
+			uint32(x),
+			uint32(y),
+			uint32(width),
+			uint32(height),
+		)
+	}
+
+	const (
+		configureWindow = 12
+		xywhMask        = 0xF
+	)
+	return c.exec(
+		newXRequest(configureWindow, 0).
+			u32(window).
+			u16(xywhMask).
+			pad(2).
+			u32(uint32(x)).
+			u32(uint32(y)).
+			u32(uint32(width)).
+			u32(uint32(height)),
+	)
+}
+
+// sendClientMessage sends a client message of the given type about the window
+// to the root window, where the window manager receives it. data can contain
+// up to 5 values.
+func (c *xConn) sendClientMessage(window uint32, messageType string, data ...uint32) error {
+	typ, err := c.atom(messageType)
+	if err != nil {
+		return err
+	}
+	const (
+		clientMessage              = 33
+		substructureNotifyMask     = 1 << 19
+		substructureRedirectMask   = 1 << 20
+		substructureRedirectNotify = substructureNotifyMask | substructureRedirectMask
+	)
+	event := make([]byte, 32)
+	event[0] = clientMessage
+	event[1] = 32 // The format of the data.
+	le.PutUint32(event[4:], window)
+	le.PutUint32(event[8:], typ)
+	for i, d := range data {
+		le.PutUint32(event[12+4*i:], d)
+	}
+	return c.exec(c.sendEvent(c.screen.root, substructureRedirectNotify, event))
+}
+
+// ShowMessage opens a message box displaying the given message and waits for
+// the user to close it. X11 has no message box of its own, so this runs the
+// first of zenity, kdialog and xmessage that is installed. Without any of them
+// the message is printed to stderr.
+func ShowMessage(caption, message string) {
+	dialogs := [][]string{
+		{"zenity", "--info", "--title", caption, "--text", message},
+		{"kdialog", "--title", caption, "--msgbox", message},
+		{"xmessage", "-center", "-title", caption, message},
+	}
+	for _, args := range dialogs {
+		if _, err := exec.LookPath(args[0]); err == nil {
+			exec.Command(args[0], args[1:]...).Run()
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%s\n%s\n", caption, message)
+}
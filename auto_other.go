@@ -0,0 +1,126 @@
+//go:build !windows && !linux
+
+package auto
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"time"
+)
+
+// unsupportedDriver is the DefaultDriver on operating systems that this
+// package does not support. Everything returns ErrUnsupported so that code
+// using this package still compiles there.
+type unsupportedDriver struct{}
+
+var platformDriver Driver = unsupportedDriver{}
+
+func (unsupportedDriver) Inject(events ...InputEvent) error {
+	return ErrUnsupported
+}
+
+func (unsupportedDriver) MousePosition() (x, y int, err error) {
+	return 0, 0, ErrUnsupported
+}
+
+func (unsupportedDriver) Type(text string, options TypeOptions) error {
+	return ErrUnsupported
+}
+
+func (unsupportedDriver) KeysForRune(r rune) ([]InputEvent, error) {
+	return nil, ErrUnsupported
+}
+
+func (unsupportedDriver) IsKeyDown(key uint16) (bool, error) {
+	return false, ErrUnsupported
+}
+
+func (unsupportedDriver) IsKeyToggled(key uint16) (bool, error) {
+	return false, ErrUnsupported
+}
+
+func (unsupportedDriver) Monitors() ([]Monitor, error) {
+	return nil, ErrUnsupported
+}
+
+func (unsupportedDriver) CaptureScreen(x, y, width, height int) (image.Image, error) {
+	return nil, ErrUnsupported
+}
+
+func (unsupportedDriver) Windows() ([]Window, error) {
+	return nil, ErrUnsupported
+}
+
+func (unsupportedDriver) ForegroundWindow() (Window, error) {
+	return Window{}, ErrUnsupported
+}
+
+func (unsupportedDriver) UpdateWindow(w Window) Window {
+	return w
+}
+
+func (unsupportedDriver) BringWindowToForeground(w Window) error {
+	return ErrUnsupported
+}
+
+func (unsupportedDriver) RestoreWindow(w Window) {}
+
+func (unsupportedDriver) MaximizeWindow(w Window) {}
+
+func (unsupportedDriver) MinimizeWindow(w Window) {}
+
+func (unsupportedDriver) HideWindow(w Window) {}
+
+func (unsupportedDriver) ShowWindow(w Window) {}
+
+func (unsupportedDriver) WindowInnerPosition(w Window) (x, y, width, height int, err error) {
+	return 0, 0, 0, 0, ErrUnsupported
+}
+
+func (unsupportedDriver) SetWindowInnerPosition(w Window, x, y, width, height int) error {
+	return ErrUnsupported
+}
+
+func (unsupportedDriver) WindowOuterPosition(w Window) (x, y, width, height int, err error) {
+	return 0, 0, 0, 0, ErrUnsupported
+}
+
+func (unsupportedDriver) SetWindowOuterPosition(w Window, x, y, width, height int) error {
+	return ErrUnsupported
+}
+
+func (unsupportedDriver) ClipboardText() (string, error) {
+	return "", ErrUnsupported
+}
+
+func (unsupportedDriver) SetClipboardText(text string) error {
+	return ErrUnsupported
+}
+
+func (unsupportedDriver) SetOnKeyboardEvent(f func(*KeyboardEvent)) {}
+
+func (unsupportedDriver) SetOnMouseEvent(f func(*MouseEvent)) {}
+
+func (unsupportedDriver) SetOnClipboardChange(f func()) {}
+
+func (unsupportedDriver) SetCancelableKeys(keys ...uint16) error {
+	return ErrUnsupported
+}
+
+// doubleClickLimits returns the Windows defaults since we cannot ask the
+// system.
+func doubleClickLimits() (limit time.Duration, width, height int) {
+	return 500 * time.Millisecond, 4, 4
+}
+
+// dragThreshold returns a common default since we cannot ask the system.
+func dragThreshold() int {
+	return 4
+}
+
+// ShowMessage prints the given message to stderr since there is no message box
+// on this operating system.
+func ShowMessage(caption, message string) {
+	fmt.Fprintf(os.Stderr, "%s\n%s\n", caption, message)
+}
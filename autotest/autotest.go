@@ -0,0 +1,818 @@
+// Package autotest provides a virtual desktop that you can use in place of the
+// real one to test code that uses package auto, on any operating system.
+//
+// Create a Desktop, install it with auto.SetDriver and run your automation
+// code. Afterwards, check the Desktop's state, e.g. the log of all injected
+// keyboard and mouse events, the mouse position, the windows or the
+// clipboard:
+//
+//	d := autotest.New()
+//	auto.SetDriver(d)
+//	defer auto.SetDriver(nil)
+//
+//	auto.ClickLeftMouseAt(10, 20)
+//
+//	// d.Events() is now:
+//	// {Type: auto.InputMoveTo, X: 10, Y: 20}
+//	// {Type: auto.InputButtonDown, Button: auto.LeftMouseButton}
+//	// {Type: auto.InputButtonUp, Button: auto.LeftMouseButton}
+package autotest
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"sync"
+
+	"github.com/gonutz/auto"
+)
+
+// Desktop is an in-memory desktop that implements auto.Driver. It has
+// monitors, windows, a clipboard, a mouse cursor and a frame buffer that
+// CaptureScreen reads from. All input that is injected into it is logged and
+// can be read with Events.
+//
+// A Desktop is safe for concurrent use.
+type Desktop struct {
+	mu       sync.Mutex
+	monitors []auto.Monitor
+	// windows are in z-order, the foreground window comes first.
+	windows    []window
+	lastHandle uintptr
+	// screen spans the outer hull of all monitors.
+	screen    *image.RGBA
+	mouseX    int
+	mouseY    int
+	clipboard string
+	// primary is the second clipboard that is used instead of clipboard
+	// after UsePrimarySelection(true).
+	primary    string
+	usePrimary bool
+	// keysDown are the keys and mouse buttons that are held down, toggled are
+	// the lock keys that are on.
+	keysDown map[uint16]bool
+	toggled  map[uint16]bool
+	// touches are the fingers on the touch screen by contact number.
+	touches    map[int]image.Point
+	penX       int
+	penY       int
+	pen        auto.PenState
+	events     []auto.InputEvent
+	cancelable []uint16
+	onKeyboard func(*auto.KeyboardEvent)
+	onMouse    func(*auto.MouseEvent)
+	onClip     func()
+}
+
+var (
+	_ auto.Driver                 = (*Desktop)(nil)
+	_ auto.CancelableKeysDriver   = (*Desktop)(nil)
+	_ auto.PrimarySelectionDriver = (*Desktop)(nil)
+)
+
+// window is a window on the Desktop. restore is the outer boundary that the
+// window gets when it is restored after being maximized.
+type window struct {
+	auto.Window
+	restore auto.Rectangle
+}
+
+// New creates a Desktop with the given monitors. If no monitors are given,
+// the Desktop has a single primary monitor of 1920x1080 pixels at 0,0.
+//
+// The mouse starts in the center of the primary monitor, or the first monitor
+// if none is primary.
+func New(monitors ...auto.Monitor) *Desktop {
+	if len(monitors) == 0 {
+		r := auto.Rectangle{Width: 1920, Height: 1080}
+		monitors = []auto.Monitor{{Rectangle: r, WorkArea: r, Primary: true}}
+	}
+	d := &Desktop{
+		monitors: append([]auto.Monitor(nil), monitors...),
+		keysDown: make(map[uint16]bool),
+		toggled:  make(map[uint16]bool),
+		touches:  make(map[int]image.Point),
+	}
+
+	hull := d.hull()
+	d.screen = image.NewRGBA(image.Rect(
+		hull.X, hull.Y, hull.X+hull.Width, hull.Y+hull.Height,
+	))
+
+	m := monitors[0]
+	for _, monitor := range monitors {
+		if monitor.Primary {
+			m = monitor
+			break
+		}
+	}
+	d.mouseX = m.X + m.Width/2
+	d.mouseY = m.Y + m.Height/2
+
+	return d
+}
+
+// hull returns the outer hull of all monitors.
+func (d *Desktop) hull() auto.Rectangle {
+	left := d.monitors[0].X
+	top := d.monitors[0].Y
+	right := left + d.monitors[0].Width
+	bottom := top + d.monitors[0].Height
+	for _, m := range d.monitors {
+		if m.X < left {
+			left = m.X
+		}
+		if m.Y < top {
+			top = m.Y
+		}
+		if m.X+m.Width > right {
+			right = m.X + m.Width
+		}
+		if m.Y+m.Height > bottom {
+			bottom = m.Y + m.Height
+		}
+	}
+	return auto.Rectangle{X: left, Y: top, Width: right - left, Height: bottom - top}
+}
+
+// Screen returns the frame buffer that CaptureScreen reads from. Its bounds
+// are the outer hull of all monitors, in virtual screen coordinates. Draw into
+// it to simulate what is visible on the screen.
+//
+// The image is not guarded by the Desktop's lock, do not draw into it while
+// the code under test captures the screen.
+func (d *Desktop) Screen() *image.RGBA {
+	return d.screen
+}
+
+// AddWindow puts the given window on top of all other windows and makes it
+// the foreground window. The window gets a new, unique Handle, the given one
+// is ignored. If its Content is empty, it is set to the window's Rectangle.
+// The returned Window is the one stored in the Desktop.
+//
+// Remember to set Visible for regular windows, only visible windows can be in
+// the foreground.
+func (d *Desktop) AddWindow(w auto.Window) auto.Window {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.lastHandle++
+	w.Handle = d.lastHandle
+	if w.Content == (auto.Rectangle{}) {
+		w.Content = w.Rectangle
+	}
+	d.windows = append([]window{{Window: w, restore: w.Rectangle}}, d.windows...)
+	return w
+}
+
+// RemoveWindow closes the given window. It does nothing if the window is not
+// on the Desktop.
+func (d *Desktop) RemoveWindow(w auto.Window) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if i := d.find(w); i != -1 {
+		d.windows = append(d.windows[:i], d.windows[i+1:]...)
+	}
+}
+
+// find returns the index of the given window in d.windows or -1 if it is not
+// on the Desktop.
+func (d *Desktop) find(w auto.Window) int {
+	for i := range d.windows {
+		if d.windows[i].Handle == w.Handle {
+			return i
+		}
+	}
+	return -1
+}
+
+// Events returns a copy of the log of all events that were injected into the
+// Desktop, in order. Type is logged as the key presses that produce the text.
+func (d *Desktop) Events() []auto.InputEvent {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]auto.InputEvent(nil), d.events...)
+}
+
+// ClearEvents empties the log of injected events.
+func (d *Desktop) ClearEvents() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.events = nil
+}
+
+// UserInput simulates the user generating the given input. It changes the
+// Desktop, e.g. the mouse position, and calls the keyboard and mouse
+// callbacks with events that are not marked as Injected, just like Inject
+// does, but the events are not logged.
+func (d *Desktop) UserInput(events ...auto.InputEvent) error {
+	return d.input(false, events)
+}
+
+// Inject logs the given events and applies them to the Desktop. The keyboard
+// and mouse callbacks are called with events that are marked as Injected.
+func (d *Desktop) Inject(events ...auto.InputEvent) error {
+	return d.input(true, events)
+}
+
+func (d *Desktop) input(injected bool, events []auto.InputEvent) error {
+	d.mu.Lock()
+	var callbacks []func()
+	var err error
+	for _, e := range events {
+		var calls []func()
+		calls, err = d.apply(e, injected)
+		if err != nil {
+			break
+		}
+		if injected {
+			d.events = append(d.events, e)
+		}
+		callbacks = append(callbacks, calls...)
+	}
+	d.mu.Unlock()
+
+	// The callbacks might use the Desktop themselves, so we call them without
+	// holding the lock.
+	for _, f := range callbacks {
+		f()
+	}
+	return err
+}
+
+var buttonEvents = map[auto.MouseButton][2]auto.MouseEventType{
+	auto.LeftMouseButton:   {auto.LeftMouseDown, auto.LeftMouseUp},
+	auto.RightMouseButton:  {auto.RightMouseDown, auto.RightMouseUp},
+	auto.MiddleMouseButton: {auto.MiddleMouseDown, auto.MiddleMouseUp},
+	auto.X1MouseButton:     {auto.XButton1Down, auto.XButton1Up},
+	auto.X2MouseButton:     {auto.XButton2Down, auto.XButton2Up},
+}
+
+// buttonKeys are the Key... constants of the mouse buttons.
+var buttonKeys = map[auto.MouseButton]uint16{
+	auto.LeftMouseButton:   auto.KeyLeftButton,
+	auto.RightMouseButton:  auto.KeyRightButton,
+	auto.MiddleMouseButton: auto.KeyMiddleButton,
+	auto.X1MouseButton:     auto.KeyXButton1,
+	auto.X2MouseButton:     auto.KeyXButton2,
+}
+
+var lockKeys = map[uint16]bool{
+	auto.KeyCapsLock:   true,
+	auto.KeyNumLock:    true,
+	auto.KeyScrollLock: true,
+}
+
+// keySides are the left and right keys of the generic modifier keys.
+var keySides = map[uint16][2]uint16{
+	auto.KeyShift:   {auto.KeyLeftShift, auto.KeyRightShift},
+	auto.KeyControl: {auto.KeyLeftControl, auto.KeyRightControl},
+	auto.KeyAlt:     {auto.KeyLeftAlt, auto.KeyRightAlt},
+}
+
+// apply changes the Desktop according to the given event and returns the
+// callbacks that this event triggers.
+func (d *Desktop) apply(e auto.InputEvent, injected bool) ([]func(), error) {
+	switch e.Type {
+	case auto.InputKeyDown, auto.InputKeyUp:
+		down := e.Type == auto.InputKeyDown
+		if lockKeys[e.Key] && down && !d.keysDown[e.Key] {
+			d.toggled[e.Key] = !d.toggled[e.Key]
+		}
+		d.keysDown[e.Key] = down
+		return d.keyboardEvent(&auto.KeyboardEvent{
+			Key:      e.Key,
+			Down:     e.Type == auto.InputKeyDown,
+			Injected: injected,
+		}), nil
+	case auto.InputButtonDown, auto.InputButtonUp:
+		types, ok := buttonEvents[e.Button]
+		if !ok {
+			return nil, fmt.Errorf("autotest: unknown mouse button %d", e.Button)
+		}
+		t := types[0]
+		if e.Type == auto.InputButtonUp {
+			t = types[1]
+		}
+		d.keysDown[buttonKeys[e.Button]] = e.Type == auto.InputButtonDown
+		return d.mouseEvent(t, 0, injected), nil
+	case auto.InputMoveTo:
+		d.moveMouseTo(e.X, e.Y)
+		return d.mouseEvent(auto.MouseMove, 0, injected), nil
+	case auto.InputMoveBy, auto.InputMoveRaw:
+		d.moveMouseTo(d.mouseX+e.X, d.mouseY+e.Y)
+		return d.mouseEvent(auto.MouseMove, 0, injected), nil
+	case auto.InputWheel:
+		var calls []func()
+		if e.WheelY != 0 {
+			calls = append(calls, d.mouseEvent(auto.MouseWheel, e.WheelY, injected)...)
+		}
+		if e.WheelX != 0 {
+			calls = append(calls, d.mouseEvent(auto.MouseWheelHorizontal, e.WheelX, injected)...)
+		}
+		return calls, nil
+	case auto.InputTouchDown:
+		if _, ok := d.touches[e.Contact]; ok {
+			return nil, fmt.Errorf("autotest: touch contact %d is already down", e.Contact)
+		}
+		d.touches[e.Contact] = image.Pt(e.X, e.Y)
+		return nil, nil
+	case auto.InputTouchMove, auto.InputTouchUp:
+		if _, ok := d.touches[e.Contact]; !ok {
+			return nil, fmt.Errorf("autotest: touch contact %d is not down", e.Contact)
+		}
+		if e.Type == auto.InputTouchUp {
+			delete(d.touches, e.Contact)
+		} else {
+			d.touches[e.Contact] = image.Pt(e.X, e.Y)
+		}
+		return nil, nil
+	case auto.InputPen:
+		if e.Pen.InRange {
+			d.penX, d.penY = e.X, e.Y
+		}
+		d.pen = e.Pen
+		return nil, nil
+	}
+	return nil, fmt.Errorf("autotest: unknown input event type %d", e.Type)
+}
+
+func (d *Desktop) keyboardEvent(e *auto.KeyboardEvent) []func() {
+	if f := d.onKeyboard; f != nil {
+		return []func(){func() { f(e) }}
+	}
+	return nil
+}
+
+func (d *Desktop) mouseEvent(t auto.MouseEventType, wheel float64, injected bool) []func() {
+	if f := d.onMouse; f != nil {
+		e := &auto.MouseEvent{
+			Type:     t,
+			X:        d.mouseX,
+			Y:        d.mouseY,
+			Wheel:    wheel,
+			Injected: injected,
+		}
+		return []func(){func() { f(e) }}
+	}
+	return nil
+}
+
+// moveMouseTo moves the mouse to the given position, keeping it inside the
+// outer hull of the monitors like a real desktop does.
+func (d *Desktop) moveMouseTo(x, y int) {
+	hull := d.hull()
+	d.mouseX = clamp(x, hull.X, hull.X+hull.Width-1)
+	d.mouseY = clamp(y, hull.Y, hull.Y+hull.Height-1)
+}
+
+func clamp(x, min, max int) int {
+	if x < min {
+		return min
+	}
+	if x > max {
+		return max
+	}
+	return x
+}
+
+// MousePosition returns the current mouse position in screen coordinates.
+func (d *Desktop) MousePosition() (x, y int, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.mouseX, d.mouseY, nil
+}
+
+// IsKeyDown reports whether the given key or mouse button is held down, by
+// injected or user input. The generic keys KeyShift, KeyControl and KeyAlt are
+// down if either of their sides is down.
+func (d *Desktop) IsKeyDown(key uint16) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	sides := keySides[key]
+	return d.keysDown[key] || d.keysDown[sides[0]] || d.keysDown[sides[1]], nil
+}
+
+// IsKeyToggled reports whether the given lock key is on. All lock keys start
+// out off and every press of a lock key toggles it.
+func (d *Desktop) IsKeyToggled(key uint16) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.toggled[key], nil
+}
+
+// Touches returns the positions of the fingers that are currently on the touch
+// screen, by contact number.
+func (d *Desktop) Touches() map[int]image.Point {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	touches := make(map[int]image.Point, len(d.touches))
+	for contact, p := range d.touches {
+		touches[contact] = p
+	}
+	return touches
+}
+
+// Pen returns the last position and the current state of the pen. The position
+// does not change while the pen is out of range.
+func (d *Desktop) Pen() (x, y int, state auto.PenState) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.penX, d.penY, d.pen
+}
+
+// usKey is a key on the US keyboard layout, possibly with Shift held down.
+type usKey struct {
+	key   uint16
+	shift bool
+}
+
+// usLayout maps the characters on a US keyboard to the keys producing them.
+var usLayout = func() map[rune]usKey {
+	m := map[rune]usKey{
+		' ':  {key: auto.KeySpace},
+		'\r': {key: auto.KeyEnter},
+		'\t': {key: auto.KeyTab},
+		'\b': {key: auto.KeyBackspace},
+	}
+	for r := 'a'; r <= 'z'; r++ {
+		m[r] = usKey{key: uint16(r - 'a' + 'A')}
+		m[r-'a'+'A'] = usKey{key: uint16(r - 'a' + 'A'), shift: true}
+	}
+	keys := []struct {
+		plain, shifted rune
+		key            uint16
+	}{
+		{'1', '!', auto.Key1},
+		{'2', '@', auto.Key2},
+		{'3', '#', auto.Key3},
+		{'4', '$', auto.Key4},
+		{'5', '%', auto.Key5},
+		{'6', '^', auto.Key6},
+		{'7', '&', auto.Key7},
+		{'8', '*', auto.Key8},
+		{'9', '(', auto.Key9},
+		{'0', ')', auto.Key0},
+		{'-', '_', auto.KeyOemMinus},
+		{'=', '+', auto.KeyOemPlus},
+		{'[', '{', auto.KeyOem4},
+		{']', '}', auto.KeyOem6},
+		{'\\', '|', auto.KeyOem5},
+		{';', ':', auto.KeyOem1},
+		{'\'', '"', auto.KeyOem7},
+		{'`', '~', auto.KeyOem3},
+		{',', '<', auto.KeyOemComma},
+		{'.', '>', auto.KeyOemPeriod},
+		{'/', '?', auto.KeyOem2},
+	}
+	for _, k := range keys {
+		m[k.plain] = usKey{key: k.key}
+		m[k.shifted] = usKey{key: k.key, shift: true}
+	}
+	return m
+}()
+
+// Type injects the key presses that produce the given text on a US keyboard
+// layout. Capital letters and other shifted characters are typed with
+// KeyShift held down. Characters that are not on the US layout cannot be typed
+// and nothing is injected in that case.
+//
+// The mode and delay of the options are ignored, the Desktop never sleeps.
+func (d *Desktop) Type(text string, options auto.TypeOptions) error {
+	var events []auto.InputEvent
+	for _, r := range text {
+		k, ok := usLayout[r]
+		if !ok {
+			return fmt.Errorf("autotest: cannot type %q, only the characters on a US keyboard can be typed", r)
+		}
+		if k.shift {
+			events = append(events, keyDown(auto.KeyShift))
+		}
+		events = append(events, keyDown(k.key), keyUp(k.key))
+		if k.shift {
+			events = append(events, keyUp(auto.KeyShift))
+		}
+	}
+	return d.Inject(events...)
+}
+
+// KeysForRune returns the key presses that produce r on a US keyboard layout,
+// the same that Type uses. For other characters the error wraps
+// auto.ErrNotOnLayout.
+func (d *Desktop) KeysForRune(r rune) ([]auto.InputEvent, error) {
+	k, ok := usLayout[r]
+	if !ok {
+		return nil, fmt.Errorf("autotest: cannot type %q: %w", r, auto.ErrNotOnLayout)
+	}
+	if k.shift {
+		return []auto.InputEvent{keyDown(auto.KeyShift), keyDown(k.key), keyUp(k.key), keyUp(auto.KeyShift)}, nil
+	}
+	return []auto.InputEvent{keyDown(k.key), keyUp(k.key)}, nil
+}
+
+func keyDown(key uint16) auto.InputEvent {
+	return auto.InputEvent{Type: auto.InputKeyDown, Key: key}
+}
+
+func keyUp(key uint16) auto.InputEvent {
+	return auto.InputEvent{Type: auto.InputKeyUp, Key: key}
+}
+
+// Monitors returns the monitors that the Desktop was created with.
+func (d *Desktop) Monitors() ([]auto.Monitor, error) {
+	return append([]auto.Monitor(nil), d.monitors...), nil
+}
+
+// CaptureScreen returns a copy of the given area of the frame buffer, see
+// Screen. Parts of the area that lie outside the frame buffer are transparent
+// in the image.
+func (d *Desktop) CaptureScreen(x, y, width, height int) (image.Image, error) {
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("autotest: capture area is empty")
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), d.screen, image.Pt(x, y), draw.Src)
+	return img, nil
+}
+
+// Windows returns all windows on the Desktop, the foreground window first.
+func (d *Desktop) Windows() ([]auto.Window, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	windows := make([]auto.Window, len(d.windows))
+	for i := range d.windows {
+		windows[i] = d.windows[i].Window
+	}
+	return windows, nil
+}
+
+// ForegroundWindow returns the top-most window that is visible and not
+// minimized.
+func (d *Desktop) ForegroundWindow() (auto.Window, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, w := range d.windows {
+		if w.Visible && !w.Minimized {
+			return w.Window, nil
+		}
+	}
+	return auto.Window{}, errors.New("autotest: no foreground window")
+}
+
+// UpdateWindow returns the current state of the given window. If the window is
+// not on the Desktop, it is returned unchanged.
+func (d *Desktop) UpdateWindow(w auto.Window) auto.Window {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if i := d.find(w); i != -1 {
+		return d.windows[i].Window
+	}
+	return w
+}
+
+// BringWindowToForeground puts the given window on top of all other windows.
+func (d *Desktop) BringWindowToForeground(w auto.Window) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i := d.find(w)
+	if i == -1 {
+		return errors.New("autotest: window not found")
+	}
+	d.toFront(i)
+	return nil
+}
+
+// toFront moves d.windows[i] to the start of the z-order.
+func (d *Desktop) toFront(i int) {
+	w := d.windows[i]
+	copy(d.windows[1:i+1], d.windows[:i])
+	d.windows[0] = w
+}
+
+// RestoreWindow unminimizes and unmaximizes the given window.
+func (d *Desktop) RestoreWindow(w auto.Window) {
+	d.change(w, func(w *window) {
+		if w.Maximized {
+			w.setOuter(w.restore)
+		}
+		w.Maximized = false
+		w.Minimized = false
+	})
+}
+
+// MaximizeWindow makes the given window cover the work area of the monitor
+// that contains its center.
+func (d *Desktop) MaximizeWindow(w auto.Window) {
+	d.change(w, func(w *window) {
+		if !w.Maximized {
+			w.restore = w.Rectangle
+		}
+		w.setOuter(d.monitorAt(
+			w.X+w.Width/2,
+			w.Y+w.Height/2,
+		).WorkArea)
+		w.Maximized = true
+		w.Minimized = false
+	})
+}
+
+// monitorAt returns the monitor containing the given point or the first
+// monitor if none does.
+func (d *Desktop) monitorAt(x, y int) auto.Monitor {
+	for _, m := range d.monitors {
+		if m.X <= x && x < m.X+m.Width && m.Y <= y && y < m.Y+m.Height {
+			return m
+		}
+	}
+	return d.monitors[0]
+}
+
+// MinimizeWindow minimizes the given window.
+func (d *Desktop) MinimizeWindow(w auto.Window) {
+	d.change(w, func(w *window) {
+		w.Minimized = true
+	})
+}
+
+// HideWindow makes the given window invisible.
+func (d *Desktop) HideWindow(w auto.Window) {
+	d.change(w, func(w *window) {
+		w.Visible = false
+	})
+}
+
+// ShowWindow makes the given window visible.
+func (d *Desktop) ShowWindow(w auto.Window) {
+	d.change(w, func(w *window) {
+		w.Visible = true
+	})
+}
+
+// change calls f on the Desktop's copy of the given window. It does nothing if
+// the window is not on the Desktop.
+func (d *Desktop) change(w auto.Window, f func(*window)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if i := d.find(w); i != -1 {
+		f(&d.windows[i])
+	}
+}
+
+// setOuter moves the window's outer boundary to r. The border around the
+// content keeps its size.
+func (w *window) setOuter(r auto.Rectangle) {
+	w.Content.X += r.X - w.X
+	w.Content.Y += r.Y - w.Y
+	w.Content.Width += r.Width - w.Width
+	w.Content.Height += r.Height - w.Height
+	w.Rectangle = r
+}
+
+// setInner moves the window's content to r. The border around the content
+// keeps its size.
+func (w *window) setInner(r auto.Rectangle) {
+	w.X += r.X - w.Content.X
+	w.Y += r.Y - w.Content.Y
+	w.Width += r.Width - w.Content.Width
+	w.Height += r.Height - w.Content.Height
+	w.Content = r
+}
+
+// WindowInnerPosition returns the content boundaries of the given window.
+func (d *Desktop) WindowInnerPosition(w auto.Window) (x, y, width, height int, err error) {
+	w = d.UpdateWindow(w)
+	return w.Content.X, w.Content.Y, w.Content.Width, w.Content.Height, nil
+}
+
+// SetWindowInnerPosition moves the content of the given window. The window is
+// no longer maximized afterwards.
+func (d *Desktop) SetWindowInnerPosition(w auto.Window, x, y, width, height int) error {
+	return d.setPosition(w, func(w *window) {
+		w.setInner(auto.Rectangle{X: x, Y: y, Width: width, Height: height})
+	})
+}
+
+// WindowOuterPosition returns the outer boundaries of the given window.
+func (d *Desktop) WindowOuterPosition(w auto.Window) (x, y, width, height int, err error) {
+	w = d.UpdateWindow(w)
+	return w.X, w.Y, w.Width, w.Height, nil
+}
+
+// SetWindowOuterPosition moves the outer boundaries of the given window. The
+// window is no longer maximized afterwards.
+func (d *Desktop) SetWindowOuterPosition(w auto.Window, x, y, width, height int) error {
+	return d.setPosition(w, func(w *window) {
+		w.setOuter(auto.Rectangle{X: x, Y: y, Width: width, Height: height})
+	})
+}
+
+func (d *Desktop) setPosition(w auto.Window, f func(*window)) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i := d.find(w)
+	if i == -1 {
+		return errors.New("autotest: window not found")
+	}
+	win := &d.windows[i]
+	f(win)
+	win.Maximized = false
+	win.restore = win.Rectangle
+	return nil
+}
+
+// ClipboardText returns the text on the Desktop's clipboard.
+func (d *Desktop) ClipboardText() (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.usePrimary {
+		return d.primary, nil
+	}
+	return d.clipboard, nil
+}
+
+// SetClipboardText sets the text on the Desktop's clipboard and calls the
+// clipboard callback.
+func (d *Desktop) SetClipboardText(text string) error {
+	d.mu.Lock()
+	if d.usePrimary {
+		d.primary = text
+	} else {
+		d.clipboard = text
+	}
+	f := d.onClip
+	d.mu.Unlock()
+
+	if f != nil {
+		f()
+	}
+	return nil
+}
+
+// UsePrimarySelection switches the clipboard methods between the Desktop's
+// clipboard and a second one, like the X11 PRIMARY selection.
+func (d *Desktop) UsePrimarySelection(primary bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.usePrimary = primary
+}
+
+// SetOnKeyboardEvent sets the callback for keyboard events from Inject, Type
+// and UserInput. Calling Cancel on the events has no effect.
+func (d *Desktop) SetOnKeyboardEvent(f func(*auto.KeyboardEvent)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.onKeyboard = f
+}
+
+// SetOnMouseEvent sets the callback for mouse events from Inject and
+// UserInput. Calling Cancel on the events has no effect.
+func (d *Desktop) SetOnMouseEvent(f func(*auto.MouseEvent)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.onMouse = f
+}
+
+// SetCancelableKeys records the given keys, see CancelableKeys. Calling Cancel
+// on events has no effect either way.
+func (d *Desktop) SetCancelableKeys(keys ...uint16) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.cancelable = append([]uint16(nil), keys...)
+	return nil
+}
+
+// CancelableKeys returns the keys of the last call to SetCancelableKeys.
+func (d *Desktop) CancelableKeys() []uint16 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]uint16(nil), d.cancelable...)
+}
+
+// SetOnClipboardChange sets the callback for SetClipboardText.
+func (d *Desktop) SetOnClipboardChange(f func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.onClip = f
+}
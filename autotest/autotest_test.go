@@ -0,0 +1,257 @@
+package autotest_test
+
+import (
+	"image"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gonutz/auto"
+	"github.com/gonutz/auto/autotest"
+)
+
+// useDesktop makes a new Desktop the driver of package auto for the rest of
+// the test.
+func useDesktop(t *testing.T, monitors ...auto.Monitor) *autotest.Desktop {
+	d := autotest.New(monitors...)
+	auto.SetDriver(d)
+	t.Cleanup(func() { auto.SetDriver(nil) })
+	return d
+}
+
+func TestClickLeftMouseAtIsLogged(t *testing.T) {
+	d := useDesktop(t)
+
+	if err := auto.ClickLeftMouseAt(10, 20); err != nil {
+		t.Fatal(err)
+	}
+	want := []auto.InputEvent{
+		{Type: auto.InputMoveTo, X: 10, Y: 20},
+		{Type: auto.InputButtonDown, Button: auto.LeftMouseButton},
+		{Type: auto.InputButtonUp, Button: auto.LeftMouseButton},
+	}
+	if got := d.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %v\nwant %v", got, want)
+	}
+	if x, y, _ := d.MousePosition(); x != 10 || y != 20 {
+		t.Errorf("want the mouse at 10,20 but it is at %d,%d", x, y)
+	}
+
+	d.ClearEvents()
+	if events := d.Events(); len(events) != 0 {
+		t.Errorf("ClearEvents left %v", events)
+	}
+}
+
+func TestLockKeysToggleOnPress(t *testing.T) {
+	d := autotest.New()
+	down := auto.InputEvent{Type: auto.InputKeyDown, Key: auto.KeyCapsLock}
+	up := auto.InputEvent{Type: auto.InputKeyUp, Key: auto.KeyCapsLock}
+
+	steps := []struct {
+		event   auto.InputEvent
+		toggled bool
+	}{
+		{down, true},
+		// Holding the key down repeats the press, which does not toggle.
+		{down, true},
+		{up, true},
+		{down, false},
+		{up, false},
+	}
+	for i, s := range steps {
+		if err := d.Inject(s.event); err != nil {
+			t.Fatal(err)
+		}
+		if toggled, _ := d.IsKeyToggled(auto.KeyCapsLock); toggled != s.toggled {
+			t.Errorf("step %d: want toggled %v but have %v", i, s.toggled, toggled)
+		}
+	}
+	if toggled, _ := d.IsKeyToggled(auto.KeyNumLock); toggled {
+		t.Error("NumLock is on without pressing it")
+	}
+}
+
+func TestTouchErrors(t *testing.T) {
+	d := autotest.New()
+	touch := func(typ auto.InputEventType, contact int) error {
+		return d.Inject(auto.InputEvent{Type: typ, Contact: contact, X: contact, Y: 5})
+	}
+
+	if err := touch(auto.InputTouchDown, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := touch(auto.InputTouchDown, 0); err == nil {
+		t.Error("the same finger was put down twice")
+	}
+	if err := touch(auto.InputTouchMove, 1); err == nil {
+		t.Error("a finger that is not down was moved")
+	}
+	if err := touch(auto.InputTouchUp, 1); err == nil {
+		t.Error("a finger that is not down was lifted")
+	}
+	if err := touch(auto.InputTouchDown, 1); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int]image.Point{0: {0, 5}, 1: {1, 5}}; !reflect.DeepEqual(d.Touches(), want) {
+		t.Errorf("want touches %v but have %v", want, d.Touches())
+	}
+	if err := touch(auto.InputTouchUp, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := touch(auto.InputTouchUp, 1); err != nil {
+		t.Fatal(err)
+	}
+	if touches := d.Touches(); len(touches) != 0 {
+		t.Errorf("fingers %v are still down", touches)
+	}
+}
+
+func TestMaximizeAndRestoreKeepTheRestoreRectangle(t *testing.T) {
+	screen := auto.Rectangle{Width: 800, Height: 600}
+	workArea := auto.Rectangle{Y: 20, Width: 800, Height: 580}
+	d := useDesktop(t, auto.Monitor{Rectangle: screen, WorkArea: workArea, Primary: true})
+
+	outer := auto.Rectangle{X: 100, Y: 100, Width: 300, Height: 200}
+	inner := auto.Rectangle{X: 105, Y: 130, Width: 290, Height: 165}
+	w := d.AddWindow(auto.Window{Rectangle: outer, Content: inner, Visible: true})
+
+	w.Maximize()
+	if !w.Maximized || w.Rectangle != workArea {
+		t.Errorf("the window does not cover the work area: %+v", w)
+	}
+	// Maximizing again must not overwrite the restore rectangle.
+	w.Maximize()
+	w.Restore()
+	if w.Maximized || w.Rectangle != outer || w.Content != inner {
+		t.Errorf("the window was not restored: %+v", w)
+	}
+
+	// Restoring a minimized window does not move it.
+	w.Minimize()
+	if !w.Minimized {
+		t.Error("the window is not minimized")
+	}
+	w.Restore()
+	if w.Minimized || w.Rectangle != outer {
+		t.Errorf("the window was not restored: %+v", w)
+	}
+}
+
+func TestCallbacksRunOutsideTheLock(t *testing.T) {
+	d := autotest.New()
+	calls := 0
+	// The callbacks use the Desktop, which deadlocks if they are called
+	// while it is locked. They run after all events of one Inject call are
+	// applied, so each event is injected on its own.
+	d.SetOnKeyboardEvent(func(e *auto.KeyboardEvent) {
+		if down, _ := d.IsKeyDown(e.Key); down != e.Down || !e.Injected {
+			t.Errorf("wrong keyboard event %+v", e)
+		}
+		calls++
+	})
+	d.SetOnMouseEvent(func(e *auto.MouseEvent) {
+		if x, y, _ := d.MousePosition(); x != e.X || y != e.Y {
+			t.Errorf("mouse event at %d,%d but the mouse is at %d,%d", e.X, e.Y, x, y)
+		}
+		calls++
+	})
+	d.SetOnClipboardChange(func() {
+		if text, _ := d.ClipboardText(); text != "copied" {
+			t.Errorf("the clipboard has %q in the callback", text)
+		}
+		calls++
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.Inject(auto.InputEvent{Type: auto.InputKeyDown, Key: auto.KeyA})
+		d.Inject(auto.InputEvent{Type: auto.InputKeyUp, Key: auto.KeyA})
+		d.Inject(auto.InputEvent{Type: auto.InputMoveTo, X: 3, Y: 4})
+		d.SetClipboardText("copied")
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("the callbacks deadlocked")
+	}
+	if calls != 4 {
+		t.Errorf("want 4 callbacks but have %d", calls)
+	}
+}
+
+func TestXButtons(t *testing.T) {
+	d := useDesktop(t)
+	var events []auto.MouseEventType
+	d.SetOnMouseEvent(func(e *auto.MouseEvent) {
+		events = append(events, e.Type)
+	})
+
+	for _, tt := range []struct {
+		n        int
+		key      uint16
+		down, up auto.MouseEventType
+	}{
+		{1, auto.KeyXButton1, auto.XButton1Down, auto.XButton1Up},
+		{2, auto.KeyXButton2, auto.XButton2Down, auto.XButton2Up},
+	} {
+		events = nil
+		if err := auto.PressXButton(tt.n); err != nil {
+			t.Fatal(err)
+		}
+		if down, _ := d.IsKeyDown(tt.key); !down {
+			t.Errorf("X button %d is not down", tt.n)
+		}
+		if err := auto.ReleaseXButton(tt.n); err != nil {
+			t.Fatal(err)
+		}
+		if down, _ := d.IsKeyDown(tt.key); down {
+			t.Errorf("X button %d is still down", tt.n)
+		}
+		if want := []auto.MouseEventType{tt.down, tt.up}; !reflect.DeepEqual(events, want) {
+			t.Errorf("X button %d: want mouse events %v but have %v", tt.n, want, events)
+		}
+	}
+}
+
+func TestUsePrimarySelection(t *testing.T) {
+	d := useDesktop(t)
+
+	if err := auto.SetClipboardText("copied"); err != nil {
+		t.Fatal(err)
+	}
+	auto.UsePrimarySelection(true)
+	if text, _ := auto.ClipboardText(); text != "" {
+		t.Errorf("the primary selection has %q from the clipboard", text)
+	}
+	if err := auto.SetClipboardText("selected"); err != nil {
+		t.Fatal(err)
+	}
+	auto.UsePrimarySelection(false)
+	if text, _ := d.ClipboardText(); text != "copied" {
+		t.Errorf("want the clipboard to still have %q but it has %q", "copied", text)
+	}
+	d.UsePrimarySelection(true)
+	if text, _ := auto.ClipboardText(); text != "selected" {
+		t.Errorf("want the primary selection %q but have %q", "selected", text)
+	}
+}
+
+func TestSetCancelableKeys(t *testing.T) {
+	d := useDesktop(t)
+
+	if err := auto.SetCancelableKeys(auto.KeyA, auto.KeyLeftButton); err != nil {
+		t.Fatal(err)
+	}
+	want := []uint16{auto.KeyA, auto.KeyLeftButton}
+	if keys := d.CancelableKeys(); !reflect.DeepEqual(keys, want) {
+		t.Errorf("want cancelable keys %v but have %v", want, keys)
+	}
+	if err := auto.SetCancelableKeys(); err != nil {
+		t.Fatal(err)
+	}
+	if keys := d.CancelableKeys(); len(keys) != 0 {
+		t.Errorf("keys %v are still cancelable", keys)
+	}
+}
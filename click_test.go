@@ -0,0 +1,81 @@
+package auto
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClickMouseNSendsAllClicksAtOnce(t *testing.T) {
+	for n := 1; n <= 3; n++ {
+		d := useFakeDriver(t)
+		if err := ClickMouseN(RightMouseButton, n); err != nil {
+			t.Fatal(err)
+		}
+		var want []InputEvent
+		for i := 0; i < n; i++ {
+			want = append(want, buttonDown(RightMouseButton), buttonUp(RightMouseButton))
+		}
+		if !reflect.DeepEqual(d.injected, [][]InputEvent{want}) {
+			t.Errorf("%d clicks:\ngot  %v\nwant %v", n, d.injected, want)
+		}
+	}
+}
+
+func TestClickMouseNRejectsInvalidCounts(t *testing.T) {
+	d := useFakeDriver(t)
+	for _, n := range []int{0, -1} {
+		if err := ClickMouseN(LeftMouseButton, n); err == nil {
+			t.Errorf("ClickMouseN accepted n = %d", n)
+		}
+	}
+	if err := ClickMouseN(MouseButton(99), 2); err == nil {
+		t.Error("ClickMouseN accepted an invalid button")
+	}
+	if len(d.injected) != 0 {
+		t.Errorf("input was sent for invalid clicks: %v", d.injected)
+	}
+}
+
+func TestDoubleClickLeftMouseAtSendsMoveAndClicksAtOnce(t *testing.T) {
+	d := useFakeDriver(t)
+	if err := DoubleClickLeftMouseAt(10, 20); err != nil {
+		t.Fatal(err)
+	}
+	want := []InputEvent{
+		moveTo(10, 20),
+		buttonDown(LeftMouseButton), buttonUp(LeftMouseButton),
+		buttonDown(LeftMouseButton), buttonUp(LeftMouseButton),
+	}
+	if !reflect.DeepEqual(d.injected, [][]InputEvent{want}) {
+		t.Errorf("\ngot  %v\nwant %v", d.injected, want)
+	}
+}
+
+func TestXButtons(t *testing.T) {
+	d := useFakeDriver(t)
+	for _, n := range []int{0, 3} {
+		if _, err := xMouseButton(n); err == nil {
+			t.Errorf("xMouseButton accepted %d", n)
+		}
+		if err := ClickXButton(n); err == nil {
+			t.Errorf("ClickXButton accepted %d", n)
+		}
+	}
+	if len(d.injected) != 0 {
+		t.Errorf("input was sent for invalid X buttons: %v", d.injected)
+	}
+
+	for n, b := range map[int]MouseButton{1: X1MouseButton, 2: X2MouseButton} {
+		d := useFakeDriver(t)
+		if err := PressXButton(n); err != nil {
+			t.Fatal(err)
+		}
+		if err := ReleaseXButton(n); err != nil {
+			t.Fatal(err)
+		}
+		want := [][]InputEvent{{buttonDown(b)}, {buttonUp(b)}}
+		if !reflect.DeepEqual(d.injected, want) {
+			t.Errorf("X button %d:\ngot  %v\nwant %v", n, d.injected, want)
+		}
+	}
+}
@@ -0,0 +1,563 @@
+package auto
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// On X11 the clipboard is not a buffer in the X server. Instead, a client owns
+// a selection, usually CLIPBOARD, and other clients ask the owner to convert
+// the selection to the data type they want. This means that after
+// SetClipboardText returns, we still have to serve the text to other clients.
+// A background goroutine with its own X connection does this. Note that the
+// text is gone once the program exits, unless a clipboard manager takes it
+// over.
+
+var clipboardSelection = struct {
+	sync.Mutex
+	name string
+}{name: "CLIPBOARD"}
+
+// UsePrimarySelection switches the clipboard functions between the PRIMARY
+// and CLIPBOARD selections.
+func (linuxDriver) UsePrimarySelection(primary bool) {
+	clipboardSelection.Lock()
+	defer clipboardSelection.Unlock()
+	if primary {
+		clipboardSelection.name = "PRIMARY"
+	} else {
+		clipboardSelection.name = "CLIPBOARD"
+	}
+}
+
+func selectionName() string {
+	clipboardSelection.Lock()
+	defer clipboardSelection.Unlock()
+	return clipboardSelection.name
+}
+
+// ClipboardText asks the owner of the clipboard selection for its text.
+func (linuxDriver) ClipboardText() (string, error) {
+	selection := selectionName()
+
+	// If we own the selection ourselves, we need not ask the X server.
+	if text, ok := owner.text(selection); ok {
+		return text, nil
+	}
+
+	c, err := openDisplay(true)
+	if err != nil {
+		return "", err
+	}
+	defer c.close()
+
+	selectionAtom, err := c.atom(selection)
+	if err != nil {
+		return "", err
+	}
+	property, err := c.atom("AUTO_SELECTION")
+	if err != nil {
+		return "", err
+	}
+	incr, err := c.atom("INCR")
+	if err != nil {
+		return "", err
+	}
+	window, err := c.createHelperWindow()
+	if err != nil {
+		return "", err
+	}
+
+	for _, targetName := range []string{"UTF8_STRING", "STRING"} {
+		target, err := c.atom(targetName)
+		if err != nil {
+			return "", err
+		}
+
+		const convertSelection = 24
+		err = c.post(
+			newXRequest(convertSelection, 0).
+				u32(window).
+				u32(selectionAtom).
+				u32(target).
+				u32(property).
+				u32(0), // CurrentTime
+		)
+		if err != nil {
+			return "", err
+		}
+
+		notify, err := c.waitForEvent(xSelectionNotify, func(e []byte) bool {
+			return le.Uint32(e[8:]) == window
+		})
+		if err != nil {
+			return "", err
+		}
+		if le.Uint32(notify[20:]) == 0 {
+			// The owner refused to convert to this target, or there is no
+			// owner.
+			continue
+		}
+
+		prop, err := c.getProperty(window, property, 0, true)
+		if err != nil {
+			return "", err
+		}
+		if prop.typ != incr {
+			return string(prop.data), nil
+		}
+
+		// The text is large and comes in chunks. Deleting the property, which
+		// we did when reading it, tells the owner to send the next chunk. An
+		// empty chunk ends the transfer.
+		var text []byte
+		for {
+			_, err := c.waitForEvent(xPropertyNotify, func(e []byte) bool {
+				const newValue = 0
+				return le.Uint32(e[4:]) == window &&
+					le.Uint32(e[8:]) == property &&
+					e[16] == newValue
+			})
+			if err != nil {
+				return "", err
+			}
+			chunk, err := c.getProperty(window, property, 0, true)
+			if err != nil {
+				return "", err
+			}
+			if len(chunk.data) == 0 {
+				return string(text), nil
+			}
+			text = append(text, chunk.data...)
+		}
+	}
+
+	return "", nil
+}
+
+func (linuxDriver) SetClipboardText(text string) error {
+	return owner.set(selectionName(), text)
+}
+
+// These are the X11 events that we handle for selections.
+const (
+	xPropertyNotify   = 28
+	xSelectionClear   = 29
+	xSelectionRequest = 30
+	xSelectionNotify  = 31
+)
+
+const xPropertyChangeMask = 1 << 22
+
+// createHelperWindow creates an invisible window that we need to own and
+// convert selections. It receives property change events.
+func (c *xConn) createHelperWindow() (uint32, error) {
+	window, err := c.newID()
+	if err != nil {
+		return 0, err
+	}
+	const (
+		createWindow = 1
+		inputOnly    = 2
+		cwEventMask  = 0x800
+	)
+	// Depth 0 and visual 0 mean that we copy them from the parent window.
+	err = c.exec(
+		newXRequest(createWindow, 0).
+			u32(window).
+			u32(c.screen.root).
+			i16(-1).
+			i16(-1).
+			u16(1).
+			u16(1).
+			u16(0). // Border width.
+			u16(inputOnly).
+			u32(0).
+			u32(cwEventMask).
+			u32(xPropertyChangeMask),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return window, nil
+}
+
+// waitForEvent reads events from the connection until one of the given type
+// matches. Other events are discarded. If no matching event arrives in time,
+// an error is returned.
+func (c *xConn) waitForEvent(typ byte, matches func([]byte) bool) ([]byte, error) {
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case e, ok := <-c.events:
+			if !ok {
+				return nil, c.broken()
+			}
+			if e[0]&0x7F == typ && matches(e) {
+				return e, nil
+			}
+		case <-timeout:
+			return nil, errors.New("timeout waiting for the selection owner")
+		}
+	}
+}
+
+// owner is our background selection owner. It is started on the first call to
+// SetClipboardText.
+var owner selectionOwner
+
+type selectionOwner struct {
+	mu      sync.Mutex
+	conn    *xConn
+	window  uint32
+	texts   map[uint32]string
+	names   map[uint32]string
+	targets map[string]uint32
+	// transfers are the running INCR transfers, per requestor window and
+	// property.
+	transfers map[[2]uint32]*incrTransfer
+}
+
+type incrTransfer struct {
+	data []byte
+	typ  uint32
+}
+
+// text returns the text that we currently own for the selection, if any.
+func (o *selectionOwner) text(selection string) (string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for atom, name := range o.names {
+		if name == selection {
+			text, ok := o.texts[atom]
+			return text, ok
+		}
+	}
+	return "", false
+}
+
+func (o *selectionOwner) set(selection, text string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.conn != nil && o.conn.broken() != nil {
+		o.conn = nil
+	}
+	if o.conn == nil {
+		if err := o.start(); err != nil {
+			return err
+		}
+	}
+
+	c := o.conn
+	selectionAtom, err := c.atom(selection)
+	if err != nil {
+		return err
+	}
+	o.texts[selectionAtom] = text
+	o.names[selectionAtom] = selection
+
+	const (
+		setSelectionOwner = 22
+		getSelectionOwner = 23
+	)
+	err = c.post(
+		newXRequest(setSelectionOwner, 0).
+			u32(o.window).
+			u32(selectionAtom).
+			u32(0), // CurrentTime
+	)
+	if err != nil {
+		return err
+	}
+	reply, err := c.roundTrip(newXRequest(getSelectionOwner, 0).u32(selectionAtom))
+	if err != nil {
+		return err
+	}
+	if le.Uint32(reply[8:]) != o.window {
+		delete(o.texts, selectionAtom)
+		return errors.New("could not become the owner of the " + selection + " selection")
+	}
+	return nil
+}
+
+// start opens the owner's X connection and starts serving requests. o.mu must
+// be locked.
+func (o *selectionOwner) start() error {
+	c, err := openDisplay(true)
+	if err != nil {
+		return err
+	}
+	window, err := c.createHelperWindow()
+	if err != nil {
+		c.close()
+		return err
+	}
+
+	o.targets = make(map[string]uint32)
+	for _, name := range []string{
+		"TARGETS", "TEXT", "UTF8_STRING", "INCR",
+		"text/plain", "text/plain;charset=utf-8",
+	} {
+		atom, err := c.atom(name)
+		if err != nil {
+			c.close()
+			return err
+		}
+		o.targets[name] = atom
+	}
+	o.targets["STRING"] = xAtomString
+
+	o.conn = c
+	o.window = window
+	o.texts = make(map[uint32]string)
+	o.names = make(map[uint32]string)
+	o.transfers = make(map[[2]uint32]*incrTransfer)
+
+	go o.serve(c)
+	return nil
+}
+
+func (o *selectionOwner) serve(c *xConn) {
+	for e := range c.events {
+		o.mu.Lock()
+		switch e[0] & 0x7F {
+		case xSelectionRequest:
+			o.handleRequest(
+				le.Uint32(e[4:]),  // time
+				le.Uint32(e[12:]), // requestor
+				le.Uint32(e[16:]), // selection
+				le.Uint32(e[20:]), // target
+				le.Uint32(e[24:]), // property
+			)
+		case xSelectionClear:
+			// Another client owns the selection now.
+			delete(o.texts, le.Uint32(e[12:]))
+		case xPropertyNotify:
+			const deleted = 1
+			if e[16] == deleted {
+				o.continueTransfer(le.Uint32(e[4:]), le.Uint32(e[8:]))
+			}
+		}
+		o.mu.Unlock()
+	}
+}
+
+// handleRequest converts our selection for the requestor. o.mu must be locked.
+func (o *selectionOwner) handleRequest(time, requestor, selection, target, property uint32) {
+	c := o.conn
+	if property == 0 {
+		// Obsolete clients do not give a property, the target is used instead.
+		property = target
+	}
+
+	text, owned := o.texts[selection]
+	ok := owned
+	if owned {
+		switch target {
+		case o.targets["TARGETS"]:
+			targets := []uint32{
+				o.targets["TARGETS"],
+				o.targets["UTF8_STRING"],
+				o.targets["STRING"],
+				o.targets["TEXT"],
+				o.targets["text/plain"],
+				o.targets["text/plain;charset=utf-8"],
+			}
+			data := make([]byte, 4*len(targets))
+			for i, t := range targets {
+				le.PutUint32(data[4*i:], t)
+			}
+			c.post(changeProperty(requestor, property, xAtomAtom, 32, data))
+		case o.targets["UTF8_STRING"], o.targets["STRING"], o.targets["TEXT"],
+			o.targets["text/plain"], o.targets["text/plain;charset=utf-8"]:
+			typ := target
+			if target == o.targets["TEXT"] {
+				typ = o.targets["UTF8_STRING"]
+			}
+			o.sendText(requestor, property, typ, []byte(text))
+		default:
+			ok = false
+		}
+	}
+
+	if !ok {
+		property = 0 // Refuse the conversion.
+	}
+	event := make([]byte, 32)
+	event[0] = xSelectionNotify
+	le.PutUint32(event[4:], time)
+	le.PutUint32(event[8:], requestor)
+	le.PutUint32(event[12:], selection)
+	le.PutUint32(event[16:], target)
+	le.PutUint32(event[20:], property)
+	c.post(c.sendEvent(requestor, 0, event))
+}
+
+// sendText writes the text to the requestor's property. Text that is too large
+// for a single request is sent incrementally with the INCR protocol.
+func (o *selectionOwner) sendText(requestor, property, typ uint32, text []byte) {
+	c := o.conn
+	if len(text) <= o.chunkSize() {
+		c.post(changeProperty(requestor, property, typ, 8, text))
+		return
+	}
+
+	// Tell the requestor the size of the data and wait for it to delete the
+	// property, then send chunk after chunk.
+	o.transfers[[2]uint32{requestor, property}] = &incrTransfer{data: text, typ: typ}
+	const changeWindowAttributes = 2
+	const cwEventMask = 0x800
+	c.post(
+		newXRequest(changeWindowAttributes, 0).
+			u32(requestor).
+			u32(cwEventMask).
+			u32(xPropertyChangeMask),
+	)
+	size := make([]byte, 4)
+	le.PutUint32(size, uint32(len(text)))
+	c.post(changeProperty(requestor, property, o.targets["INCR"], 32, size))
+}
+
+// continueTransfer sends the next chunk of an INCR transfer after the
+// requestor deleted the property. o.mu must be locked.
+func (o *selectionOwner) continueTransfer(requestor, property uint32) {
+	key := [2]uint32{requestor, property}
+	t, ok := o.transfers[key]
+	if !ok {
+		return
+	}
+	n := o.chunkSize()
+	if n > len(t.data) {
+		n = len(t.data)
+	}
+	o.conn.post(changeProperty(requestor, property, t.typ, 8, t.data[:n]))
+	if n == 0 {
+		// The empty chunk ends the transfer.
+		delete(o.transfers, key)
+	}
+	t.data = t.data[n:]
+}
+
+// chunkSize is the largest amount of data that we send in one request.
+func (o *selectionOwner) chunkSize() int {
+	return o.conn.maxRequestLength - 64
+}
+
+// changeProperty returns a ChangeProperty request that replaces the window's
+// property with the given data. format is the number of bits per item, 8, 16
+// or 32.
+func changeProperty(window, property, typ uint32, format byte, data []byte) xRequest {
+	const (
+		changeProperty = 18
+		replace        = 0
+	)
+	return newXRequest(changeProperty, replace).
+		u32(window).
+		u32(property).
+		u32(typ).
+		u8(format).
+		pad(3).
+		u32(uint32(len(data) / int(format/8))).
+		bytes(data)
+}
+
+var clipboardListener struct {
+	mu       sync.Mutex
+	conn     *xConn
+	callback func()
+}
+
+// SetOnClipboardChange uses the XFixes extension to get notified when the
+// owner of the clipboard selection changes.
+func (linuxDriver) SetOnClipboardChange(f func()) {
+	clipboardListener.mu.Lock()
+	defer clipboardListener.mu.Unlock()
+
+	clipboardListener.callback = f
+
+	if f == nil {
+		if clipboardListener.conn != nil {
+			clipboardListener.conn.close()
+			clipboardListener.conn = nil
+		}
+		return
+	}
+
+	if clipboardListener.conn != nil {
+		return
+	}
+
+	c, err := listenForSelectionChanges(selectionName())
+	if err != nil {
+		return
+	}
+	clipboardListener.conn = c
+	go func() {
+		xfixes, _ := c.extension("XFIXES")
+		selectionNotify := xfixes.firstEvent
+		for e := range c.events {
+			if e[0]&0x7F == selectionNotify {
+				clipboardListener.mu.Lock()
+				callback := clipboardListener.callback
+				clipboardListener.mu.Unlock()
+				if callback != nil {
+					callback()
+				}
+			}
+		}
+	}()
+}
+
+// listenForSelectionChanges opens an X connection that receives an XFixes
+// selection notify event every time the given selection changes.
+func listenForSelectionChanges(selection string) (*xConn, error) {
+	c, err := openDisplay(true)
+	if err != nil {
+		return nil, err
+	}
+
+	xfixes, err := c.extension("XFIXES")
+	if err != nil {
+		c.close()
+		return nil, err
+	}
+	const (
+		queryVersion         = 0
+		selectSelectionInput = 2
+
+		setSelectionOwnerMask      = 1
+		selectionWindowDestroyMask = 2
+		selectionClientCloseMask   = 4
+	)
+	// The version must be queried before any other XFixes request.
+	if _, err := c.roundTrip(newXRequest(xfixes.opcode, queryVersion).u32(5).u32(0)); err != nil {
+		c.close()
+		return nil, err
+	}
+
+	window, err := c.createHelperWindow()
+	if err != nil {
+		c.close()
+		return nil, err
+	}
+	selectionAtom, err := c.atom(selection)
+	if err != nil {
+		c.close()
+		return nil, err
+	}
+	err = c.exec(
+		newXRequest(xfixes.opcode, selectSelectionInput).
+			u32(window).
+			u32(selectionAtom).
+			u32(setSelectionOwnerMask |
+				selectionWindowDestroyMask |
+				selectionClientCloseMask),
+	)
+	if err != nil {
+		c.close()
+		return nil, err
+	}
+	return c, nil
+}
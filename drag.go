@@ -0,0 +1,103 @@
+package auto
+
+import (
+	"math"
+	"time"
+)
+
+// DragOptions configure DragMouse. The zero value drags in 10 steps, 10ms
+// apart, without waiting after pressing or before releasing the button.
+type DragOptions struct {
+	// Hold is the time to wait after pressing the button, before the mouse
+	// starts moving. Some applications only start a drag after the button was
+	// held for a while.
+	Hold time.Duration
+	// Steps is the number of mouse moves from the start to the target,
+	// including the first move past the drag threshold. It defaults to 10.
+	// The last move always goes to the target, so with a threshold move at
+	// least 2 moves are made.
+	Steps int
+	// StepDelay is the time between two mouse moves. It defaults to 10ms.
+	StepDelay time.Duration
+	// Threshold is the distance in pixels that the mouse has to move with the
+	// button down before the operating system considers it a drag. The first
+	// move goes just past this distance, towards the target. It defaults to
+	// the system setting, a negative value disables the first move.
+	Threshold int
+	// DropDelay is the time to wait at the target before releasing the
+	// button. Some applications need the mouse to hover over the drop target
+	// for a while.
+	DropDelay time.Duration
+}
+
+// DragMouse presses the given button at fromX,fromY, moves the mouse to
+// toX,toY and releases the button there. See DragOptions for the details.
+//
+// The first move goes past the system's drag threshold, because many
+// applications do not start a drag for small moves. The button is always
+// released, even if moving the mouse fails.
+func DragMouse(button MouseButton, fromX, fromY, toX, toY int, options DragOptions) (err error) {
+	steps := options.Steps
+	if steps <= 0 {
+		steps = 10
+	}
+	stepDelay := options.StepDelay
+	if stepDelay <= 0 {
+		stepDelay = 10 * time.Millisecond
+	}
+	threshold := options.Threshold
+	if threshold == 0 {
+		threshold = dragThreshold()
+	}
+
+	d := driver()
+	if err := d.Inject(moveTo(fromX, fromY), buttonDown(button)); err != nil {
+		d.Inject(buttonUp(button))
+		return err
+	}
+	defer func() {
+		if releaseErr := d.Inject(buttonUp(button)); err == nil {
+			err = releaseErr
+		}
+	}()
+
+	time.Sleep(options.Hold)
+
+	startX, startY := float64(fromX), float64(fromY)
+	if threshold > 0 {
+		dx, dy := float64(toX-fromX), float64(toY-fromY)
+		dist := math.Hypot(dx, dy)
+		if dist == 0 {
+			dx, dy, dist = 1, 0, 1
+		}
+		startX += dx / dist * float64(threshold+1)
+		startY += dy / dist * float64(threshold+1)
+		if err := d.Inject(moveTo(round(startX), round(startY))); err != nil {
+			return err
+		}
+		// The threshold move counts as a step, but we always end with a move
+		// to the target.
+		if steps > 1 {
+			steps--
+		}
+	}
+
+	for i := 1; i <= steps; i++ {
+		if i > 1 || threshold > 0 {
+			time.Sleep(stepDelay)
+		}
+		t := float64(i) / float64(steps)
+		x := startX + (float64(toX)-startX)*t
+		y := startY + (float64(toY)-startY)*t
+		if err := d.Inject(moveTo(round(x), round(y))); err != nil {
+			return err
+		}
+	}
+
+	time.Sleep(options.DropDelay)
+	return nil
+}
+
+func round(x float64) int {
+	return int(math.Round(x))
+}
@@ -0,0 +1,143 @@
+package auto
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// dragMoves returns the mouse moves after pressing the button.
+func dragMoves(injected [][]InputEvent) []InputEvent {
+	var moves []InputEvent
+	for _, events := range injected[1:] {
+		for _, e := range events {
+			if e.Type == InputMoveTo {
+				moves = append(moves, e)
+			}
+		}
+	}
+	return moves
+}
+
+func TestDragMouse(t *testing.T) {
+	d := useFakeDriver(t)
+	err := DragMouse(LeftMouseButton, 0, 0, 100, 0, DragOptions{
+		Steps:     4,
+		StepDelay: time.Nanosecond,
+		Threshold: 5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]InputEvent{
+		{moveTo(0, 0), buttonDown(LeftMouseButton)},
+		// The first move goes just past the threshold, the other three share
+		// the rest of the way.
+		{moveTo(6, 0)},
+		{moveTo(37, 0)},
+		{moveTo(69, 0)},
+		{moveTo(100, 0)},
+		{buttonUp(LeftMouseButton)},
+	}
+	if !reflect.DeepEqual(d.injected, want) {
+		t.Errorf("\ngot  %v\nwant %v", d.injected, want)
+	}
+}
+
+func TestDragMouseStepCounts(t *testing.T) {
+	tests := []struct {
+		steps, threshold int
+		moves            int
+	}{
+		{0, 5, 10},
+		{10, 5, 10},
+		{2, 5, 2},
+		// The threshold move is made in addition to the final move.
+		{1, 5, 2},
+		{1, -1, 1},
+		{3, -1, 3},
+	}
+	for _, tt := range tests {
+		d := useFakeDriver(t)
+		err := DragMouse(RightMouseButton, 10, 10, 110, 60, DragOptions{
+			Steps:     tt.steps,
+			StepDelay: time.Nanosecond,
+			Threshold: tt.threshold,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		moves := dragMoves(d.injected)
+		if len(moves) != tt.moves {
+			t.Errorf("%d steps, threshold %d: want %d moves but have %d",
+				tt.steps, tt.threshold, tt.moves, len(moves))
+			continue
+		}
+		if last := moves[len(moves)-1]; last != moveTo(110, 60) {
+			t.Errorf("%d steps, threshold %d: the last move goes to %d,%d",
+				tt.steps, tt.threshold, last.X, last.Y)
+		}
+		first := moves[0]
+		dist := math.Hypot(float64(first.X-10), float64(first.Y-10))
+		if tt.threshold > 0 && dist <= float64(tt.threshold) {
+			t.Errorf("%d steps, threshold %d: the first move only goes %.1f pixels",
+				tt.steps, tt.threshold, dist)
+		}
+	}
+}
+
+func TestDragMouseWithoutThreshold(t *testing.T) {
+	d := useFakeDriver(t)
+	err := DragMouse(LeftMouseButton, 0, 0, 100, 0, DragOptions{
+		Steps:     2,
+		StepDelay: time.Nanosecond,
+		Threshold: -1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []InputEvent{moveTo(50, 0), moveTo(100, 0)}
+	if moves := dragMoves(d.injected); !reflect.DeepEqual(moves, want) {
+		t.Errorf("\ngot  %v\nwant %v", moves, want)
+	}
+}
+
+func TestDragMouseInPlace(t *testing.T) {
+	d := useFakeDriver(t)
+	err := DragMouse(LeftMouseButton, 20, 20, 20, 20, DragOptions{
+		Steps:     1,
+		Threshold: 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without a direction to the target, the threshold move goes right.
+	want := []InputEvent{moveTo(24, 20), moveTo(20, 20)}
+	if moves := dragMoves(d.injected); !reflect.DeepEqual(moves, want) {
+		t.Errorf("\ngot  %v\nwant %v", moves, want)
+	}
+}
+
+func TestDragMouseReleasesButtonOnError(t *testing.T) {
+	// Inject calls: 1 is the press, 2 the threshold move, 3 the next move.
+	for _, failAt := range []int{1, 2, 3} {
+		d := useFakeDriver(t)
+		d.failAt = failAt
+		err := DragMouse(LeftMouseButton, 0, 0, 100, 0, DragOptions{
+			Steps:     4,
+			StepDelay: time.Nanosecond,
+			Threshold: 5,
+		})
+		if err == nil {
+			t.Errorf("call %d fails: want an error", failAt)
+		}
+		if d.calls != failAt+1 {
+			t.Errorf("call %d fails: want %d Inject calls but have %d", failAt, failAt+1, d.calls)
+		}
+		last := d.injected[len(d.injected)-1]
+		if !reflect.DeepEqual(last, []InputEvent{buttonUp(LeftMouseButton)}) {
+			t.Errorf("call %d fails: the button was not released, the last input is %v", failAt, last)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package auto
+
+import (
+	"errors"
+	"image"
+	"sync"
+)
+
+// ErrUnsupported is returned by the DefaultDriver on operating systems that
+// this package does not support yet. Only Windows and Linux are supported.
+var ErrUnsupported = errors.New("auto: operating system not supported")
+
+// Driver is the platform implementation behind the functions of this package.
+// All package level functions delegate to the current driver, which is the
+// DefaultDriver unless you call SetDriver.
+//
+// You can implement your own Driver to automate something other than the
+// local desktop, to wrap the default driver, e.g. to log all calls, or to fake
+// the desktop in tests.
+type Driver interface {
+	// Inject generates the given mouse and keyboard input, in order.
+	Inject(events ...InputEvent) error
+	// MousePosition returns the mouse position in screen coordinates.
+	MousePosition() (x, y int, err error)
+	// Type writes the given text in the given mode, sleeping the given delay
+	// after each character. Line breaks are always given as '\r'. It is never
+	// called with TypeLayout, which the package implements with KeysForRune.
+	Type(text string, options TypeOptions) error
+	// KeysForRune returns the key events that type r on the current keyboard
+	// layout. For characters that are not on the layout, the error must wrap
+	// ErrNotOnLayout.
+	KeysForRune(r rune) ([]InputEvent, error)
+	// IsKeyDown reports whether the given key or mouse button is held down.
+	// The generic keys KeyShift, KeyControl and KeyAlt are down if either of
+	// their sides is down.
+	IsKeyDown(key uint16) (bool, error)
+	// IsKeyToggled reports whether the given lock key is on. It is only
+	// called with KeyCapsLock, KeyNumLock and KeyScrollLock.
+	IsKeyToggled(key uint16) (bool, error)
+
+	// Monitors returns all monitors currently connected to the computer.
+	Monitors() ([]Monitor, error)
+	// CaptureScreen returns a screen shot of the given area in virtual
+	// screen coordinates.
+	CaptureScreen(x, y, width, height int) (image.Image, error)
+
+	// Windows returns a list of all currently active windows.
+	Windows() ([]Window, error)
+	// ForegroundWindow returns the currently active window.
+	ForegroundWindow() (Window, error)
+	// UpdateWindow returns the current state of the given window.
+	UpdateWindow(w Window) Window
+	// BringWindowToForeground tries to bring the given window to the front.
+	BringWindowToForeground(w Window) error
+	// RestoreWindow unminimizes and unmaximizes the given window.
+	RestoreWindow(w Window)
+	// MaximizeWindow maximizes the given window.
+	MaximizeWindow(w Window)
+	// MinimizeWindow minimizes the given window.
+	MinimizeWindow(w Window)
+	// HideWindow hides the given window.
+	HideWindow(w Window)
+	// ShowWindow shows the given window after it was hidden.
+	ShowWindow(w Window)
+	// WindowInnerPosition returns the boundaries of the window content in
+	// screen coordinates.
+	WindowInnerPosition(w Window) (x, y, width, height int, err error)
+	// SetWindowInnerPosition sets the boundaries of the window content in
+	// screen coordinates.
+	SetWindowInnerPosition(w Window, x, y, width, height int) error
+	// WindowOuterPosition returns the boundaries of the window border in
+	// screen coordinates.
+	WindowOuterPosition(w Window) (x, y, width, height int, err error)
+	// SetWindowOuterPosition sets the boundaries of the window border in
+	// screen coordinates.
+	SetWindowOuterPosition(w Window, x, y, width, height int) error
+
+	// ClipboardText returns the contents of the clipboard as text.
+	ClipboardText() (string, error)
+	// SetClipboardText sets the contents of the clipboard to the given text.
+	SetClipboardText(text string) error
+
+	// SetOnKeyboardEvent sets the keyboard callback, nil stops listening.
+	SetOnKeyboardEvent(f func(*KeyboardEvent))
+	// SetOnMouseEvent sets the mouse callback, nil stops listening.
+	SetOnMouseEvent(f func(*MouseEvent))
+	// SetOnClipboardChange sets the clipboard callback, nil stops listening.
+	SetOnClipboardChange(f func())
+}
+
+// CancelableKeysDriver is an optional interface for drivers that can only
+// cancel the keyboard and mouse events of keys that were registered before,
+// like on X11. SetCancelableKeys calls it if the current driver implements it.
+type CancelableKeysDriver interface {
+	// SetCancelableKeys replaces the keys and mouse buttons for which
+	// calling Cancel on their events works.
+	SetCancelableKeys(keys ...uint16) error
+}
+
+// PrimarySelectionDriver is an optional interface for drivers that have a
+// second clipboard, like the PRIMARY selection on X11. UsePrimarySelection
+// calls it if the current driver implements it.
+type PrimarySelectionDriver interface {
+	// UsePrimarySelection makes the clipboard methods use the primary
+	// selection if primary is true and the regular clipboard otherwise.
+	UsePrimarySelection(primary bool)
+}
+
+// InputEvent is a single mouse, keyboard, touch or pen input for
+// Driver.Inject. Type decides which of the other fields are used.
+type InputEvent struct {
+	Type InputEventType
+	// Key is the virtual key code of InputKeyDown and InputKeyUp events, see
+	// the Key... constants.
+	Key uint16
+	// Button is the mouse button of InputButtonDown and InputButtonUp events.
+	Button MouseButton
+	// X and Y are the screen position for InputMoveTo, touch and pen events,
+	// the distance in pixels for InputMoveBy events and the raw mouse motion
+	// for InputMoveRaw events. InputTouchUp events lift the finger where it
+	// is, their position is ignored.
+	X, Y int
+	// Contact is the number of the finger for touch events, from 0 to
+	// MaxTouchContacts-1.
+	Contact int
+	// Pen is the new state of the pen for InputPen events.
+	Pen PenState
+	// WheelX and WheelY are the ticks that the wheel rotates for InputWheel
+	// events. They have the same meaning as in MoveMouseWheelBy.
+	WheelX, WheelY float64
+}
+
+// InputEventType is the concrete type of an InputEvent.
+type InputEventType int
+
+// These are the available InputEventTypes.
+const (
+	InputKeyDown InputEventType = iota + 1
+	InputKeyUp
+	InputButtonDown
+	InputButtonUp
+	InputMoveTo
+	InputMoveBy
+	InputWheel
+	// InputMoveRaw is relative mouse motion as a physical mouse generates it.
+	// The cursor moves according to the system's mouse speed and
+	// acceleration settings, applications reading raw input see the exact
+	// values.
+	InputMoveRaw
+	// InputTouchDown, InputTouchMove and InputTouchUp put a finger on the
+	// touch screen, move it and lift it. Consecutive touch events for
+	// different fingers happen at the same time, see TouchDown.
+	InputTouchDown
+	InputTouchMove
+	InputTouchUp
+	// InputPen moves the pen and changes its state, see MovePenTo.
+	InputPen
+)
+
+// MouseButton is one of the buttons of a mouse.
+type MouseButton int
+
+// These are the available MouseButtons.
+const (
+	LeftMouseButton MouseButton = iota + 1
+	RightMouseButton
+	MiddleMouseButton
+	// X1MouseButton and X2MouseButton are the side buttons that some mice
+	// have. Usually X1 means back and X2 means forward.
+	X1MouseButton
+	X2MouseButton
+)
+
+var currentDriver = struct {
+	sync.Mutex
+	driver Driver
+}{driver: platformDriver}
+
+// SetDriver replaces the driver that all functions in this package delegate
+// to. Passing nil restores the DefaultDriver.
+//
+// Callbacks that were set with SetOnKeyboardEvent, SetOnMouseEvent or
+// SetOnClipboardChange stay registered with the driver they were set on.
+func SetDriver(d Driver) {
+	if d == nil {
+		d = platformDriver
+	}
+	currentDriver.Lock()
+	currentDriver.driver = d
+	currentDriver.Unlock()
+}
+
+// DefaultDriver returns the driver for the current platform, which is used
+// unless you call SetDriver.
+func DefaultDriver() Driver {
+	return platformDriver
+}
+
+// driver returns the current driver, wrapped to keep track of the keys and
+// mouse buttons that are held down, see ReleaseAll.
+func driver() Driver {
+	return trackingDriver{baseDriver()}
+}
+
+// baseDriver returns the current driver as it was set, which is what the
+// optional driver interfaces are checked on.
+func baseDriver() Driver {
+	currentDriver.Lock()
+	defer currentDriver.Unlock()
+	return currentDriver.driver
+}
+
+func keyDown(key uint16) InputEvent {
+	return InputEvent{Type: InputKeyDown, Key: key}
+}
+
+func keyUp(key uint16) InputEvent {
+	return InputEvent{Type: InputKeyUp, Key: key}
+}
+
+func buttonDown(b MouseButton) InputEvent {
+	return InputEvent{Type: InputButtonDown, Button: b}
+}
+
+func buttonUp(b MouseButton) InputEvent {
+	return InputEvent{Type: InputButtonUp, Button: b}
+}
+
+func moveTo(x, y int) InputEvent {
+	return InputEvent{Type: InputMoveTo, X: x, Y: y}
+}
@@ -0,0 +1,41 @@
+package auto
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakeDriver records the input that it is given. All methods except Inject
+// panic.
+type fakeDriver struct {
+	Driver
+	// injected has the events of all successful Inject calls.
+	injected [][]InputEvent
+	// failAt is the number of the Inject call, counting from 1, that fails.
+	// 0 means no call fails.
+	failAt int
+	calls  int
+}
+
+func (d *fakeDriver) Inject(events ...InputEvent) error {
+	d.calls++
+	if d.calls == d.failAt {
+		return errors.New("fake injection failed")
+	}
+	d.injected = append(d.injected, events)
+	return nil
+}
+
+// useFakeDriver makes a fakeDriver the current driver for the rest of the
+// test.
+func useFakeDriver(t *testing.T) *fakeDriver {
+	d := &fakeDriver{}
+	SetDriver(d)
+	t.Cleanup(func() {
+		SetDriver(nil)
+		held.Lock()
+		held.presses = nil
+		held.Unlock()
+	})
+	return d
+}
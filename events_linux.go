@@ -0,0 +1,504 @@
+package auto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// The keyboard and mouse listener uses raw events of the X Input extension 2.
+// These are sent for all input devices, no matter which window has the focus
+// or whether another client grabbed the device. Raw events cannot be
+// intercepted though. Keys that should be cancelable are grabbed in addition,
+// see SetCancelableKeys. Raw events exist since version 2.0 of the extension,
+// we need 2.1 to also get them while another client grabs a device.
+
+// These are the XInput2 raw event types.
+const (
+	xiRawKeyPress      = 13
+	xiRawKeyRelease    = 14
+	xiRawButtonPress   = 15
+	xiRawButtonRelease = 16
+	xiRawMotion        = 17
+)
+
+// xMappingNotify is sent to all clients when the keyboard mapping changes.
+const xMappingNotify = 34
+
+// These are the modes of the AllowEvents request.
+const (
+	xAsyncPointer   = 0
+	xReplayPointer  = 2
+	xAsyncKeyboard  = 3
+	xReplayKeyboard = 5
+)
+
+// xAnyModifier makes a grab apply to all combinations of modifier keys.
+const xAnyModifier = 0x8000
+
+// buttonEvents maps X11 pointer buttons to the mouse event types for pressing
+// and releasing them.
+var buttonEvents = map[byte][2]MouseEventType{
+	xButtonLeft:    {LeftMouseDown, LeftMouseUp},
+	xButtonMiddle:  {MiddleMouseDown, MiddleMouseUp},
+	xButtonRight:   {RightMouseDown, RightMouseUp},
+	xButtonBack:    {XButton1Down, XButton1Up},
+	xButtonForward: {XButton2Down, XButton2Up},
+}
+
+var input inputListener
+
+type inputListener struct {
+	mu         sync.Mutex
+	conn       *xConn
+	keyboard   func(*KeyboardEvent)
+	mouse      func(*MouseEvent)
+	cancelable []uint16
+	// grabbedKeys and grabbedButtons are the key codes and pointer buttons
+	// that are currently grabbed on conn.
+	grabbedKeys    map[byte]bool
+	grabbedButtons map[byte]bool
+	// err is the reason why listening could not be started or the
+	// cancelable keys could not be grabbed. The driver's callback setters
+	// cannot return it, SetCancelableKeys does.
+	err error
+}
+
+func (linuxDriver) SetOnKeyboardEvent(f func(*KeyboardEvent)) {
+	input.mu.Lock()
+	defer input.mu.Unlock()
+	input.keyboard = f
+	input.update()
+}
+
+func (linuxDriver) SetOnMouseEvent(f func(*MouseEvent)) {
+	input.mu.Lock()
+	defer input.mu.Unlock()
+	input.mouse = f
+	input.update()
+}
+
+// SetCancelableKeys grabs the given keys and mouse buttons, see the package
+// level SetCancelableKeys.
+func (linuxDriver) SetCancelableKeys(keys ...uint16) error {
+	input.mu.Lock()
+	defer input.mu.Unlock()
+
+	for _, key := range keys {
+		if _, ok := keyButtons[key]; ok {
+			if _, ok := buttonEvents[keyButtons[key]]; !ok {
+				return fmt.Errorf("mouse button %d cannot be cancelled", key)
+			}
+		} else if _, ok := keySyms[key]; !ok {
+			return fmt.Errorf("key %d has no X11 equivalent", key)
+		}
+	}
+
+	input.cancelable = append([]uint16(nil), keys...)
+	if input.conn == nil {
+		return input.err
+	}
+	input.err = input.grab(input.conn)
+	return input.err
+}
+
+// update starts or stops listening, depending on whether callbacks are set.
+// Errors are kept in l.err. If listening failed, the next call tries again.
+func (l *inputListener) update() {
+	if l.keyboard == nil && l.mouse == nil {
+		if l.conn != nil {
+			l.conn.close()
+			l.conn = nil
+		}
+		l.err = nil
+		return
+	}
+
+	if l.conn != nil {
+		return
+	}
+
+	c, xi, err := listenForInput()
+	if err != nil {
+		l.err = fmt.Errorf("cannot listen for input: %w", err)
+		return
+	}
+	l.conn = c
+	l.err = l.grab(c)
+	go l.serve(c, xi)
+}
+
+// listenForInput opens an X connection that receives raw XInput2 events of
+// all keyboards and pointers.
+func listenForInput() (*xConn, xExtension, error) {
+	c, err := openDisplay(true)
+	if err != nil {
+		return nil, xExtension{}, err
+	}
+
+	xi, err := c.extension("XInputExtension")
+	if err != nil {
+		c.close()
+		return nil, xExtension{}, err
+	}
+
+	const (
+		xiSelectEvents           = 46
+		xiQueryVersion           = 47
+		xiAllMasterDevices       = 1
+		xiRawEventsMaskBits      = 1<<xiRawKeyPress | 1<<xiRawKeyRelease | 1<<xiRawButtonPress | 1<<xiRawButtonRelease | 1<<xiRawMotion
+		wantedMajor, wantedMinor = 2, 2
+	)
+
+	// Announcing version 2.1 or above makes the server send raw events even
+	// while a device is grabbed.
+	reply, err := c.roundTrip(
+		newXRequest(xi.opcode, xiQueryVersion).
+			u16(wantedMajor).
+			u16(wantedMinor),
+	)
+	if err != nil {
+		c.close()
+		return nil, xExtension{}, err
+	}
+	major, minor := le.Uint16(reply[8:]), le.Uint16(reply[10:])
+	if major < 2 || major == 2 && minor < 1 {
+		c.close()
+		return nil, xExtension{}, fmt.Errorf("the X server supports XInput %d.%d but version 2.1 is required", major, minor)
+	}
+
+	err = c.exec(
+		newXRequest(xi.opcode, xiSelectEvents).
+			u32(c.screen.root).
+			u16(1). // Number of masks.
+			pad(2).
+			u16(xiAllMasterDevices).
+			u16(1). // Mask length in 4 byte units.
+			u32(xiRawEventsMaskBits),
+	)
+	if err != nil {
+		c.close()
+		return nil, xExtension{}, err
+	}
+
+	return c, xi, nil
+}
+
+// grab replaces all grabs on c with grabs of the cancelable keys. l.mu must be
+// locked.
+func (l *inputListener) grab(c *xConn) error {
+	const (
+		grabButton   = 28
+		ungrabButton = 29
+		grabKey      = 33
+		ungrabKey    = 34
+
+		anyKey                = 0
+		anyButton             = 0
+		grabModeSync          = 0
+		grabModeAsync         = 1
+		buttonPressMask       = 1 << 2
+		buttonReleaseMask     = 1 << 3
+		ownerEventsDisallowed = 0
+	)
+
+	requests := []xRequest{
+		newXRequest(ungrabKey, anyKey).
+			u32(c.screen.root).
+			u16(xAnyModifier).
+			pad(2),
+		newXRequest(ungrabButton, anyButton).
+			u32(c.screen.root).
+			u16(xAnyModifier).
+			pad(2),
+	}
+	l.grabbedKeys = make(map[byte]bool)
+	l.grabbedButtons = make(map[byte]bool)
+
+	var mapping xKeyboardMapping
+	for _, key := range l.cancelable {
+		if button, ok := keyButtons[key]; ok {
+			requests = append(requests, newXRequest(grabButton, ownerEventsDisallowed).
+				u32(c.screen.root).
+				u16(buttonPressMask|buttonReleaseMask).
+				u8(grabModeSync).  // Pointer mode.
+				u8(grabModeAsync). // Keyboard mode.
+				u32(0).            // Confine to window.
+				u32(0).            // Cursor.
+				u8(button).
+				pad(1).
+				u16(xAnyModifier),
+			)
+			l.grabbedButtons[button] = true
+			continue
+		}
+
+		if mapping.perKeycode == 0 {
+			var err error
+			mapping, err = c.keyboardMapping()
+			if err != nil {
+				return err
+			}
+		}
+		// Grab all physical keys that produce the key symbol.
+		found := false
+		for code := int(mapping.minKeycode); code <= int(c.maxKeycode); code++ {
+			if symKeys[mapping.keySym(byte(code))] != key {
+				continue
+			}
+			requests = append(requests, newXRequest(grabKey, ownerEventsDisallowed).
+				u32(c.screen.root).
+				u16(xAnyModifier).
+				u8(byte(code)).
+				u8(grabModeAsync). // Pointer mode.
+				u8(grabModeSync).  // Keyboard mode.
+				pad(3),
+			)
+			l.grabbedKeys[byte(code)] = true
+			found = true
+		}
+		if !found {
+			return fmt.Errorf("key %d is not on the keyboard layout", key)
+		}
+	}
+
+	err := c.exec(requests...)
+	var xErr xError
+	if errors.As(err, &xErr) && xErr.code == 10 { // BadAccess
+		return errors.New("another program already grabbed one of the keys")
+	}
+	return err
+}
+
+// serve calls the callbacks for all input events that arrive on c. It returns
+// when c is closed.
+func (l *inputListener) serve(c *xConn, xi xExtension) {
+	d := inputDecoder{
+		conn:    c,
+		xi:      xi,
+		devices: make(map[uint16]bool),
+		lastX:   -1 << 31,
+	}
+	for e := range c.events {
+		switch {
+		case e[0]&0x7F == xGenericEvent && e[1] == xi.opcode:
+			d.rawEvent(l, e)
+		case e[0]&0x7F == xKeyPress:
+			d.grabbedKeyPress(l, e)
+		case e[0]&0x7F == xButtonPress:
+			d.grabbedButtonPress(l, e)
+		case e[0]&0x7F == xMappingNotify:
+			d.mapping = xKeyboardMapping{}
+		}
+	}
+}
+
+// callbacks returns the current callbacks and whether the key code and
+// pointer button are grabbed.
+func (l *inputListener) callbacks(code, button byte) (keyboard func(*KeyboardEvent), mouse func(*MouseEvent), keyGrabbed, buttonGrabbed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.keyboard, l.mouse, l.grabbedKeys[code], l.grabbedButtons[button]
+}
+
+// inputDecoder turns X11 events into KeyboardEvents and MouseEvents.
+type inputDecoder struct {
+	conn *xConn
+	xi   xExtension
+	// mapping is loaded lazily and reset when the keyboard mapping changes.
+	mapping xKeyboardMapping
+	// devices tells for every known input device whether it is an XTEST
+	// device, i.e. whether its events were generated programmatically.
+	devices map[uint16]bool
+	// The raw event for a grabbed key or button arrives just before the
+	// grabbed event itself, which does not tell whether it was injected.
+	keyInjected    [256]bool
+	buttonInjected [256]bool
+	// lastX and lastY are the last reported mouse position. Raw motion events
+	// are also sent for scrolling, these are filtered out by comparing the
+	// position.
+	lastX, lastY int
+}
+
+func (d *inputDecoder) rawEvent(l *inputListener, e []byte) {
+	typ := le.Uint16(e[8:])
+	detail := le.Uint32(e[16:])
+	source := le.Uint16(e[20:])
+	injected := d.isXTest(source)
+
+	code := byte(detail)
+	button := byte(detail)
+	keyboard, mouse, keyGrabbed, buttonGrabbed := l.callbacks(code, button)
+
+	switch typ {
+	case xiRawKeyPress, xiRawKeyRelease:
+		down := typ == xiRawKeyPress
+		if down && keyGrabbed {
+			d.keyInjected[code] = injected
+			return
+		}
+		key := d.key(code)
+		if keyboard != nil && key != 0 {
+			keyboard(&KeyboardEvent{Key: key, Down: down, Injected: injected})
+		}
+
+	case xiRawButtonPress, xiRawButtonRelease:
+		down := typ == xiRawButtonPress
+		if down && buttonGrabbed {
+			d.buttonInjected[button] = injected
+			return
+		}
+		if mouse == nil {
+			return
+		}
+		x, y, err := xMousePosition()
+		if err != nil {
+			return
+		}
+		if types, ok := buttonEvents[button]; ok {
+			t := types[1]
+			if down {
+				t = types[0]
+			}
+			mouse(&MouseEvent{Type: t, X: x, Y: y, Injected: injected})
+			return
+		}
+		// Wheel ticks are sent as presses and releases of buttons 4 to 7.
+		// Smooth scrolling devices send emulated presses in addition.
+		if !down {
+			return
+		}
+		var t MouseEventType
+		var wheel float64
+		switch button {
+		case xWheelUp:
+			t, wheel = MouseWheel, 1
+		case xWheelDown:
+			t, wheel = MouseWheel, -1
+		case xWheelLeft:
+			t, wheel = MouseWheelHorizontal, -1
+		case xWheelRight:
+			t, wheel = MouseWheelHorizontal, 1
+		default:
+			return
+		}
+		mouse(&MouseEvent{Type: t, X: x, Y: y, Wheel: wheel, Injected: injected})
+
+	case xiRawMotion:
+		if mouse == nil {
+			return
+		}
+		x, y, err := xMousePosition()
+		if err != nil || x == d.lastX && y == d.lastY {
+			return
+		}
+		d.lastX, d.lastY = x, y
+		mouse(&MouseEvent{Type: MouseMove, X: x, Y: y, Injected: injected})
+	}
+}
+
+// grabbedKeyPress handles the press of a key that was grabbed because it is
+// cancelable. The keyboard is frozen until we allow events again.
+func (d *inputDecoder) grabbedKeyPress(l *inputListener, e []byte) {
+	code := e[1]
+	keyboard, _, _, _ := l.callbacks(code, 0)
+	event := KeyboardEvent{
+		Key:      d.key(code),
+		Down:     true,
+		Injected: d.keyInjected[code],
+	}
+	if keyboard != nil && event.Key != 0 {
+		keyboard(&event)
+	}
+	mode := byte(xReplayKeyboard)
+	if event.cancelled {
+		mode = xAsyncKeyboard
+	}
+	d.conn.post(allowEvents(mode))
+}
+
+// grabbedButtonPress handles the press of a mouse button that was grabbed
+// because it is cancelable. The pointer is frozen until we allow events again.
+func (d *inputDecoder) grabbedButtonPress(l *inputListener, e []byte) {
+	button := e[1]
+	_, mouse, _, _ := l.callbacks(0, button)
+	types, ok := buttonEvents[button]
+	event := MouseEvent{
+		Type:     types[0],
+		X:        int(int16(le.Uint16(e[20:]))),
+		Y:        int(int16(le.Uint16(e[22:]))),
+		Injected: d.buttonInjected[button],
+	}
+	if mouse != nil && ok {
+		mouse(&event)
+	}
+	mode := byte(xReplayPointer)
+	if event.cancelled {
+		mode = xAsyncPointer
+	}
+	d.conn.post(allowEvents(mode))
+}
+
+func allowEvents(mode byte) xRequest {
+	const allowEvents = 35
+	return newXRequest(allowEvents, mode).u32(0) // Current time.
+}
+
+// key returns the Key... constant for the given key code, or 0 if there is
+// none.
+func (d *inputDecoder) key(code byte) uint16 {
+	if d.mapping.perKeycode == 0 {
+		m, err := d.conn.keyboardMapping()
+		if err != nil {
+			return 0
+		}
+		d.mapping = m
+	}
+	return symKeys[d.mapping.keySym(code)]
+}
+
+// isXTest returns true if the given input device is one of the XTEST devices
+// that the X server uses for fake input.
+func (d *inputDecoder) isXTest(device uint16) bool {
+	if xtest, ok := d.devices[device]; ok {
+		return xtest
+	}
+
+	// Devices can be plugged in at any time, query them when we see a new one.
+	const (
+		xiQueryDevice = 48
+		xiAllDevices  = 0
+	)
+	reply, err := d.conn.roundTrip(
+		newXRequest(d.xi.opcode, xiQueryDevice).
+			u16(xiAllDevices).
+			pad(2),
+	)
+	if err != nil {
+		return false
+	}
+	count := int(le.Uint16(reply[8:]))
+	info := reply[32:]
+	for i := 0; i < count && len(info) >= 12; i++ {
+		id := le.Uint16(info[0:])
+		classCount := int(le.Uint16(info[6:]))
+		nameLen := int(le.Uint16(info[8:]))
+		if len(info) < 12+nameLen {
+			break
+		}
+		name := string(info[12 : 12+nameLen])
+		d.devices[id] = strings.Contains(name, "XTEST")
+		info = info[12+nameLen+pad(nameLen):]
+		for j := 0; j < classCount && len(info) >= 4; j++ {
+			classLen := 4 * int(le.Uint16(info[2:]))
+			if classLen == 0 || classLen > len(info) {
+				info = nil
+				break
+			}
+			info = info[classLen:]
+		}
+	}
+	d.devices[device] = d.devices[device]
+	return d.devices[device]
+}
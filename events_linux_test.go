@@ -0,0 +1,27 @@
+package auto
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSetCancelableKeysReportsListenError(t *testing.T) {
+	t.Setenv("DISPLAY", "")
+	var d linuxDriver
+	t.Cleanup(func() {
+		d.SetOnKeyboardEvent(nil)
+		d.SetCancelableKeys()
+	})
+
+	if err := d.SetCancelableKeys(KeyA); err != nil {
+		t.Fatalf("nothing listens yet but there is an error: %v", err)
+	}
+	d.SetOnKeyboardEvent(func(*KeyboardEvent) {})
+	if err := d.SetCancelableKeys(KeyA); !errors.Is(err, errNoDisplay) {
+		t.Errorf("want the listen error but got %v", err)
+	}
+	d.SetOnKeyboardEvent(nil)
+	if err := d.SetCancelableKeys(KeyA); err != nil {
+		t.Errorf("the listen error was kept after listening stopped: %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package auto
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// held are the key and mouse button presses of this package that were not
+// released yet, in the order in which they happened.
+var held struct {
+	sync.Mutex
+	presses []InputEvent
+}
+
+// trackingDriver wraps the current driver to keep track of held input, see
+// ReleaseAll.
+type trackingDriver struct {
+	Driver
+}
+
+func (d trackingDriver) Inject(events ...InputEvent) error {
+	// If injecting fails, some of the keys might be down anyway, so we
+	// remember all of them as held. Keys are only forgotten once they were
+	// released successfully.
+	trackInput(events, true)
+	err := d.Driver.Inject(events...)
+	if err == nil {
+		trackInput(events, false)
+	}
+	return err
+}
+
+// trackInput updates held with the given events. If pressOnly is true, only
+// the presses are recorded.
+func trackInput(events []InputEvent, pressOnly bool) {
+	held.Lock()
+	defer held.Unlock()
+	for _, e := range events {
+		switch e.Type {
+		case InputKeyDown, InputButtonDown:
+			if heldIndex(e) == -1 {
+				held.presses = append(held.presses, e)
+			}
+		case InputKeyUp, InputButtonUp:
+			if !pressOnly {
+				if i := heldIndex(e); i != -1 {
+					held.presses = append(held.presses[:i], held.presses[i+1:]...)
+				}
+			}
+		}
+	}
+}
+
+// heldIndex returns the index of the press in held that has the same key or
+// button as e, or -1.
+func heldIndex(e InputEvent) int {
+	for i, p := range held.presses {
+		isKey := p.Type == InputKeyDown && (e.Type == InputKeyDown || e.Type == InputKeyUp)
+		isButton := p.Type == InputButtonDown && (e.Type == InputButtonDown || e.Type == InputButtonUp)
+		if isKey && p.Key == e.Key || isButton && p.Button == e.Button {
+			return i
+		}
+	}
+	return -1
+}
+
+// Held returns the keys and mouse buttons that were pressed through this
+// package and are not released yet, in the order in which they were pressed.
+// Input that the user generates is not included, see IsKeyDown for that.
+func Held() (keys []uint16, buttons []MouseButton) {
+	held.Lock()
+	defer held.Unlock()
+	for _, p := range held.presses {
+		if p.Type == InputKeyDown {
+			keys = append(keys, p.Key)
+		} else {
+			buttons = append(buttons, p.Button)
+		}
+	}
+	return keys, buttons
+}
+
+// ReleaseAll releases all keys and mouse buttons that were pressed through
+// this package and are not released yet, see Held, in reverse order of
+// pressing them. It tries to release everything and returns the first error.
+//
+// Defer it to make sure that your program does not leave modifiers or mouse
+// buttons held down when it panics or returns early:
+//
+//	defer auto.ReleaseAll()
+func ReleaseAll() error {
+	held.Lock()
+	presses := append([]InputEvent(nil), held.presses...)
+	held.Unlock()
+
+	var firstErr error
+	for i := len(presses) - 1; i >= 0; i-- {
+		release := new(InputSequence)
+		if presses[i].Type == InputButtonDown {
+			release.ReleaseMouse(presses[i].Button)
+		} else {
+			release.ReleaseKey(presses[i].Key)
+		}
+		if err := release.Send(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Hold presses the given keys in order, calls f and releases the keys in
+// reverse order. The keys are always released, even if pressing one of them
+// fails or f panics. It returns the error of f or, if f succeeds, the error of
+// pressing or releasing the keys.
+//
+// All keys are checked before any of them is pressed, like in InputSequence.
+// If one is invalid, nothing is pressed and f is not called. Keys that are
+// already held through this package, see Held, are neither pressed nor
+// released, they stay down after Hold returns.
+//
+// For example this selects the next two words with Ctrl+Shift held down:
+//
+//	err := auto.Hold([]uint16{auto.KeyControl, auto.KeyShift}, func() error {
+//		return new(auto.InputSequence).TypeKey(auto.KeyRight).TypeKey(auto.KeyRight).Send()
+//	})
+func Hold(keys []uint16, f func() error) (err error) {
+	check := new(InputSequence)
+	for _, key := range keys {
+		check.PressKey(key)
+	}
+	if err := check.Err(); err != nil {
+		return err
+	}
+
+	heldBefore, _ := Held()
+	var pressed []uint16
+	defer func() {
+		release := new(InputSequence)
+		for i := len(pressed) - 1; i >= 0; i-- {
+			release.ReleaseKey(pressed[i])
+		}
+		if releaseErr := release.Send(); err == nil {
+			err = releaseErr
+		}
+	}()
+	for _, key := range keys {
+		if containsKey(heldBefore, key) || containsKey(pressed, key) {
+			continue
+		}
+		// A failed injection might still have pressed the key, so we release
+		// it as well.
+		pressed = append(pressed, key)
+		if err := new(InputSequence).PressKey(key).Send(); err != nil {
+			return err
+		}
+	}
+	return f()
+}
+
+func containsKey(keys []uint16, key uint16) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ReleaseOnSignal makes the program call ReleaseAll when it receives SIGINT,
+// e.g. from Ctrl+C, or SIGTERM. After releasing, the signal is raised again so
+// the program terminates as it would have without the handler. Where this is
+// not possible, e.g. on Windows, the program exits with status 1.
+//
+// Call the returned function to remove the handler. Do not use this together
+// with your own handling of these signals, call ReleaseAll in your handler
+// instead.
+func ReleaseOnSignal() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			ReleaseAll()
+			signal.Stop(signals)
+			if p, err := os.FindProcess(os.Getpid()); err == nil && p.Signal(sig) == nil {
+				return
+			}
+			os.Exit(1)
+		case <-done:
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
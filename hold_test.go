@@ -0,0 +1,216 @@
+package auto
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHoldPressesAndReleasesKeys(t *testing.T) {
+	d := useFakeDriver(t)
+
+	called := false
+	err := Hold([]uint16{KeyControl, KeyShift}, func() error {
+		called = true
+		return TypeKey(KeyRight)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Error("f was not called")
+	}
+	want := [][]InputEvent{
+		{keyDown(KeyControl)},
+		{keyDown(KeyShift)},
+		{keyDown(KeyRight), keyUp(KeyRight)},
+		{keyUp(KeyShift), keyUp(KeyControl)},
+	}
+	if !reflect.DeepEqual(d.injected, want) {
+		t.Errorf("\ngot  %v\nwant %v", d.injected, want)
+	}
+	if keys, _ := Held(); len(keys) != 0 {
+		t.Errorf("keys %v are still held", keys)
+	}
+}
+
+func TestHoldKeepsKeysThatWereHeldBefore(t *testing.T) {
+	d := useFakeDriver(t)
+	if err := PressKey(KeyShift); err != nil {
+		t.Fatal(err)
+	}
+	d.injected = nil
+
+	err := Hold([]uint16{KeyControl, KeyShift, KeyControl}, func() error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]InputEvent{
+		{keyDown(KeyControl)},
+		{keyUp(KeyControl)},
+	}
+	if !reflect.DeepEqual(d.injected, want) {
+		t.Errorf("\ngot  %v\nwant %v", d.injected, want)
+	}
+	if keys, _ := Held(); !reflect.DeepEqual(keys, []uint16{KeyShift}) {
+		t.Errorf("want only KeyShift held but have %v", keys)
+	}
+}
+
+func TestHoldReleasesKeysWhenPressingFails(t *testing.T) {
+	d := useFakeDriver(t)
+	d.failAt = 2
+
+	called := false
+	err := Hold([]uint16{KeyControl, KeyShift, KeyAlt}, func() error {
+		called = true
+		return nil
+	})
+	if err == nil {
+		t.Error("want the error of pressing KeyShift")
+	}
+	if called {
+		t.Error("f was called although pressing a key failed")
+	}
+	// Pressing KeyShift failed but it might be down anyway.
+	want := [][]InputEvent{
+		{keyDown(KeyControl)},
+		{keyUp(KeyShift), keyUp(KeyControl)},
+	}
+	if !reflect.DeepEqual(d.injected, want) {
+		t.Errorf("\ngot  %v\nwant %v", d.injected, want)
+	}
+	if keys, _ := Held(); len(keys) != 0 {
+		t.Errorf("keys %v are still held", keys)
+	}
+}
+
+func TestHoldReleasesKeysWhenFPanics(t *testing.T) {
+	d := useFakeDriver(t)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("the panic of f was not passed on")
+			}
+		}()
+		Hold([]uint16{KeyControl}, func() error { panic("f panics") })
+	}()
+
+	want := [][]InputEvent{
+		{keyDown(KeyControl)},
+		{keyUp(KeyControl)},
+	}
+	if !reflect.DeepEqual(d.injected, want) {
+		t.Errorf("\ngot  %v\nwant %v", d.injected, want)
+	}
+}
+
+func TestHoldChecksKeysBeforePressing(t *testing.T) {
+	d := useFakeDriver(t)
+
+	for _, keys := range [][]uint16{{KeyControl, 0}, {0x1FF}, {KeyShift, KeyA, 0xFF}} {
+		called := false
+		err := Hold(keys, func() error {
+			called = true
+			return nil
+		})
+		if err == nil {
+			t.Errorf("%v: want an error for the invalid key", keys)
+		}
+		if called {
+			t.Errorf("%v: f was called with an invalid key", keys)
+		}
+	}
+	if len(d.injected) != 0 {
+		t.Errorf("input was sent for invalid keys: %v", d.injected)
+	}
+}
+
+func TestReleaseAllReleasesInReverseOrder(t *testing.T) {
+	d := useFakeDriver(t)
+	if err := PressKey(KeyA); err != nil {
+		t.Fatal(err)
+	}
+	if err := PressLeftMouse(); err != nil {
+		t.Fatal(err)
+	}
+	if err := PressKey(KeyB); err != nil {
+		t.Fatal(err)
+	}
+	d.injected = nil
+
+	if err := ReleaseAll(); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]InputEvent{
+		{keyUp(KeyB)},
+		{buttonUp(LeftMouseButton)},
+		{keyUp(KeyA)},
+	}
+	if !reflect.DeepEqual(d.injected, want) {
+		t.Errorf("\ngot  %v\nwant %v", d.injected, want)
+	}
+	if keys, buttons := Held(); len(keys) != 0 || len(buttons) != 0 {
+		t.Errorf("keys %v and buttons %v are still held", keys, buttons)
+	}
+}
+
+func TestReleaseAllContinuesAfterErrors(t *testing.T) {
+	d := useFakeDriver(t)
+	if err := PressKey(KeyA); err != nil {
+		t.Fatal(err)
+	}
+	if err := PressKey(KeyB); err != nil {
+		t.Fatal(err)
+	}
+	// Releasing KeyB fails.
+	d.failAt = d.calls + 1
+	d.injected = nil
+
+	if err := ReleaseAll(); err == nil {
+		t.Error("want the error of releasing KeyB")
+	}
+	if want := [][]InputEvent{{keyUp(KeyA)}}; !reflect.DeepEqual(d.injected, want) {
+		t.Errorf("\ngot  %v\nwant %v", d.injected, want)
+	}
+	if keys, _ := Held(); !reflect.DeepEqual(keys, []uint16{KeyB}) {
+		t.Errorf("want KeyB to stay held but have %v", keys)
+	}
+}
+
+func TestReleaseAllChecksInput(t *testing.T) {
+	d := useFakeDriver(t)
+	// A driver that is used directly can record invalid presses.
+	trackInput([]InputEvent{keyDown(0)}, true)
+
+	if err := ReleaseAll(); err == nil {
+		t.Error("want an error for the invalid key")
+	}
+	if len(d.injected) != 0 {
+		t.Errorf("input was sent for an invalid key: %v", d.injected)
+	}
+}
+
+func TestTypeChordPressesHeldKeys(t *testing.T) {
+	d := useFakeDriver(t)
+	if err := PressKey(KeyShift); err != nil {
+		t.Fatal(err)
+	}
+	if err := PressKey(KeyA); err != nil {
+		t.Fatal(err)
+	}
+	d.injected = nil
+
+	// Unlike Hold, TypeChord presses and releases every key of the chord.
+	if err := TypeChord("Shift+A"); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]InputEvent{
+		{keyDown(KeyShift)},
+		{keyDown(KeyA)},
+		{keyUp(KeyA), keyUp(KeyShift)},
+	}
+	if !reflect.DeepEqual(d.injected, want) {
+		t.Errorf("\ngot  %v\nwant %v", d.injected, want)
+	}
+}
@@ -0,0 +1,337 @@
+package auto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// keyNames are the names of all Key... constants, without the Key prefix, in
+// the order of their declaration. Keys with the same value share the first
+// name as their KeyName.
+var keyNames = []struct {
+	name string
+	key  uint16
+}{
+	{"A", KeyA},
+	{"B", KeyB},
+	{"C", KeyC},
+	{"D", KeyD},
+	{"E", KeyE},
+	{"F", KeyF},
+	{"G", KeyG},
+	{"H", KeyH},
+	{"I", KeyI},
+	{"J", KeyJ},
+	{"K", KeyK},
+	{"L", KeyL},
+	{"M", KeyM},
+	{"N", KeyN},
+	{"O", KeyO},
+	{"P", KeyP},
+	{"Q", KeyQ},
+	{"R", KeyR},
+	{"S", KeyS},
+	{"T", KeyT},
+	{"U", KeyU},
+	{"V", KeyV},
+	{"W", KeyW},
+	{"X", KeyX},
+	{"Y", KeyY},
+	{"Z", KeyZ},
+	{"0", Key0},
+	{"1", Key1},
+	{"2", Key2},
+	{"3", Key3},
+	{"4", Key4},
+	{"5", Key5},
+	{"6", Key6},
+	{"7", Key7},
+	{"8", Key8},
+	{"9", Key9},
+	{"LeftButton", KeyLeftButton},
+	{"RightButton", KeyRightButton},
+	{"MiddleButton", KeyMiddleButton},
+	{"XButton1", KeyXButton1},
+	{"XButton2", KeyXButton2},
+	{"Cancel", KeyCancel},
+	{"Backspace", KeyBackspace},
+	{"Tab", KeyTab},
+	{"Clear", KeyClear},
+	{"Enter", KeyEnter},
+	{"Shift", KeyShift},
+	{"Control", KeyControl},
+	{"Alt", KeyAlt},
+	{"Pause", KeyPause},
+	{"CapsLock", KeyCapsLock},
+	{"ImeKana", KeyImeKana},
+	{"ImeHangul", KeyImeHangul},
+	{"ImeOn", KeyImeOn},
+	{"ImeJunja", KeyImeJunja},
+	{"ImeFinal", KeyImeFinal},
+	{"ImeHanja", KeyImeHanja},
+	{"ImeKanji", KeyImeKanji},
+	{"ImeOff", KeyImeOff},
+	{"Escape", KeyEscape},
+	{"ImeConvert", KeyImeConvert},
+	{"ImeNonConvert", KeyImeNonConvert},
+	{"ImeAccept", KeyImeAccept},
+	{"ImeModeChange", KeyImeModeChange},
+	{"Space", KeySpace},
+	{"PageUp", KeyPageUp},
+	{"PageDown", KeyPageDown},
+	{"End", KeyEnd},
+	{"Home", KeyHome},
+	{"Left", KeyLeft},
+	{"Up", KeyUp},
+	{"Right", KeyRight},
+	{"Down", KeyDown},
+	{"Select", KeySelect},
+	{"Print", KeyPrint},
+	{"Execute", KeyExecute},
+	{"PrintScreen", KeyPrintScreen},
+	{"Insert", KeyInsert},
+	{"Delete", KeyDelete},
+	{"Help", KeyHelp},
+	{"LeftWin", KeyLeftWin},
+	{"RightWin", KeyRightWin},
+	{"Apps", KeyApps},
+	{"Sleep", KeySleep},
+	{"Num0", KeyNum0},
+	{"Num1", KeyNum1},
+	{"Num2", KeyNum2},
+	{"Num3", KeyNum3},
+	{"Num4", KeyNum4},
+	{"Num5", KeyNum5},
+	{"Num6", KeyNum6},
+	{"Num7", KeyNum7},
+	{"Num8", KeyNum8},
+	{"Num9", KeyNum9},
+	{"Multiply", KeyMultiply},
+	{"Plus", KeyPlus},
+	{"Separator", KeySeparator},
+	{"Minus", KeyMinus},
+	{"Decimal", KeyDecimal},
+	{"Divide", KeyDivide},
+	{"F1", KeyF1},
+	{"F2", KeyF2},
+	{"F3", KeyF3},
+	{"F4", KeyF4},
+	{"F5", KeyF5},
+	{"F6", KeyF6},
+	{"F7", KeyF7},
+	{"F8", KeyF8},
+	{"F9", KeyF9},
+	{"F10", KeyF10},
+	{"F11", KeyF11},
+	{"F12", KeyF12},
+	{"F13", KeyF13},
+	{"F14", KeyF14},
+	{"F15", KeyF15},
+	{"F16", KeyF16},
+	{"F17", KeyF17},
+	{"F18", KeyF18},
+	{"F19", KeyF19},
+	{"F20", KeyF20},
+	{"F21", KeyF21},
+	{"F22", KeyF22},
+	{"F23", KeyF23},
+	{"F24", KeyF24},
+	{"NumLock", KeyNumLock},
+	{"ScrollLock", KeyScrollLock},
+	{"OemNecEqual", KeyOemNecEqual},
+	{"OemFjJisho", KeyOemFjJisho},
+	{"OemFjMasshou", KeyOemFjMasshou},
+	{"OemFjTouroku", KeyOemFjTouroku},
+	{"OemFjLoya", KeyOemFjLoya},
+	{"OemFjRoya", KeyOemFjRoya},
+	{"LeftShift", KeyLeftShift},
+	{"RightShift", KeyRightShift},
+	{"LeftControl", KeyLeftControl},
+	{"RightControl", KeyRightControl},
+	{"LeftAlt", KeyLeftAlt},
+	{"RightAlt", KeyRightAlt},
+	{"BrowserBack", KeyBrowserBack},
+	{"BrowserForward", KeyBrowserForward},
+	{"BrowserRefresh", KeyBrowserRefresh},
+	{"BrowserStop", KeyBrowserStop},
+	{"BrowserSearch", KeyBrowserSearch},
+	{"BrowserFavorites", KeyBrowserFavorites},
+	{"BrowserHome", KeyBrowserHome},
+	{"VolumeMute", KeyVolumeMute},
+	{"VolumeDown", KeyVolumeDown},
+	{"VolumeUp", KeyVolumeUp},
+	{"MediaNextTrack", KeyMediaNextTrack},
+	{"MediaPreviousTrack", KeyMediaPreviousTrack},
+	{"MediaStop", KeyMediaStop},
+	{"MediaPlayPause", KeyMediaPlayPause},
+	{"LaunchMail", KeyLaunchMail},
+	{"LaunchMediaSelect", KeyLaunchMediaSelect},
+	{"LaunchApp1", KeyLaunchApp1},
+	{"LaunchApp2", KeyLaunchApp2},
+	{"OemPlus", KeyOemPlus},
+	{"OemComma", KeyOemComma},
+	{"OemMinus", KeyOemMinus},
+	{"OemPeriod", KeyOemPeriod},
+	{"Oem1", KeyOem1},
+	{"Oem2", KeyOem2},
+	{"Oem3", KeyOem3},
+	{"Oem4", KeyOem4},
+	{"Oem5", KeyOem5},
+	{"Oem6", KeyOem6},
+	{"Oem7", KeyOem7},
+	{"Oem8", KeyOem8},
+	{"OemAx", KeyOemAx},
+	{"Oem102", KeyOem102},
+	{"IcoHelp", KeyIcoHelp},
+	{"Ico00", KeyIco00},
+	{"ImeProcessKey", KeyImeProcessKey},
+	{"IcoClear", KeyIcoClear},
+	{"UnicodePacket", KeyUnicodePacket},
+	{"OemReset", KeyOemReset},
+	{"OemJump", KeyOemJump},
+	{"OemPa1", KeyOemPa1},
+	{"OemPa2", KeyOemPa2},
+	{"OemPa3", KeyOemPa3},
+	{"OemWsControl", KeyOemWsControl},
+	{"OemCuSel", KeyOemCuSel},
+	{"OemAttn", KeyOemAttn},
+	{"OemFinish", KeyOemFinish},
+	{"OemCopy", KeyOemCopy},
+	{"OemAuto", KeyOemAuto},
+	{"OemEnlw", KeyOemEnlw},
+	{"OemNBackTab", KeyOemNBackTab},
+	{"Attn", KeyAttn},
+	{"CrSel", KeyCrSel},
+	{"ExSel", KeyExSel},
+	{"ErEof", KeyErEof},
+	{"Play", KeyPlay},
+	{"Zoom", KeyZoom},
+	{"NoName", KeyNoName},
+	{"Pa1", KeyPa1},
+	{"OemClear", KeyOemClear},
+}
+
+// keyAliases are common names of keys that ParseKey understands as well.
+var keyAliases = map[string]uint16{
+	"Ctrl":      KeyControl,
+	"Win":       KeyLeftWin,
+	"Windows":   KeyLeftWin,
+	"Super":     KeyLeftWin,
+	"Esc":       KeyEscape,
+	"Return":    KeyEnter,
+	"Del":       KeyDelete,
+	"Ins":       KeyInsert,
+	"PgUp":      KeyPageUp,
+	"PgDn":      KeyPageDown,
+	"Menu":      KeyApps,
+	"Break":     KeyPause,
+	"PrtSc":     KeyPrintScreen,
+	"LeftCtrl":  KeyLeftControl,
+	"RightCtrl": KeyRightControl,
+}
+
+// keysByName maps the lower case names and aliases of all keys to the keys.
+var keysByName = func() map[string]uint16 {
+	m := make(map[string]uint16)
+	for _, k := range keyNames {
+		name := strings.ToLower(k.name)
+		if _, ok := m[name]; !ok {
+			m[name] = k.key
+		}
+	}
+	for name, key := range keyAliases {
+		m[strings.ToLower(name)] = key
+	}
+	return m
+}()
+
+// ParseKey returns the key with the given name, which is the name of a Key...
+// constant without the Key prefix, e.g. "PageDown" for KeyPageDown. Case does
+// not matter and the Key prefix is optional. Common aliases like "Ctrl",
+// "Esc", "Del" or "PgDn" work as well, and so do hexadecimal virtual key
+// codes like "0x22".
+func ParseKey(name string) (uint16, error) {
+	s := strings.ToLower(strings.TrimSpace(name))
+	if key, ok := keysByName[s]; ok {
+		return key, nil
+	}
+	if key, ok := keysByName[strings.TrimPrefix(s, "key")]; ok {
+		return key, nil
+	}
+	if strings.HasPrefix(s, "0x") {
+		code, err := strconv.ParseUint(s[2:], 16, 8)
+		if err == nil && code >= 1 && code <= 0xFE {
+			return uint16(code), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown key %q", name)
+}
+
+// KeyName returns the name of the given key, which is the name of its Key...
+// constant without the Key prefix, e.g. "F5" for KeyF5. ParseKey turns it
+// back into the key. Keys without a constant are named by their hexadecimal
+// virtual key code, e.g. "0x07".
+func KeyName(key uint16) string {
+	for _, k := range keyNames {
+		if k.key == key {
+			return k.name
+		}
+	}
+	return fmt.Sprintf("0x%02X", key)
+}
+
+// ParseChord parses a key combination like "Ctrl+Shift+S" into its keys, see
+// ParseKey for the key names. The keys are returned in the order in which
+// they are pressed.
+func ParseChord(chord string) ([]uint16, error) {
+	var keys []uint16
+	for _, name := range strings.Split(chord, "+") {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid key chord %q, a key name is missing", chord)
+		}
+		key, err := ParseKey(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key chord %q: %w", chord, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// TypeChord presses the keys of the given key combination, e.g.
+// "Ctrl+Shift+S", in order and releases them in reverse order, see
+// ParseChord. All pressed keys are released, even if pressing one of them
+// fails.
+func TypeChord(chord string) error {
+	keys, err := ParseChord(chord)
+	if err != nil {
+		return err
+	}
+	return typeChord(keys)
+}
+
+func typeChord(keys []uint16) (err error) {
+	d := driver()
+	pressed := 0
+	defer func() {
+		var release []InputEvent
+		for i := pressed - 1; i >= 0; i-- {
+			release = append(release, keyUp(keys[i]))
+		}
+		if len(release) > 0 {
+			if releaseErr := d.Inject(release...); err == nil {
+				err = releaseErr
+			}
+		}
+	}()
+	for _, key := range keys {
+		// A failed injection might still have pressed the key, so we release
+		// it as well.
+		pressed++
+		if err := d.Inject(keyDown(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
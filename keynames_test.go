@@ -0,0 +1,142 @@
+package auto
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// keyConstants returns all exported Key... constants from keys.go, by name.
+func keyConstants(t *testing.T) map[string]uint16 {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "keys.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("auto", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make(map[string]uint16)
+	for _, name := range pkg.Scope().Names() {
+		c, ok := pkg.Scope().Lookup(name).(*types.Const)
+		if !ok || !c.Exported() || !strings.HasPrefix(name, "Key") {
+			continue
+		}
+		value, ok := constant.Uint64Val(c.Val())
+		if !ok {
+			t.Fatalf("%s is not an integer constant", name)
+		}
+		keys[name] = uint16(value)
+	}
+	if len(keys) < 100 {
+		t.Fatalf("found only %d Key... constants in keys.go", len(keys))
+	}
+	return keys
+}
+
+func TestEveryKeyHasAName(t *testing.T) {
+	for name, key := range keyConstants(t) {
+		// Constants with the same value share one name, so the name might
+		// not be this constant's name but must lead back to its value.
+		keyName := KeyName(key)
+		if strings.HasPrefix(keyName, "0x") {
+			t.Errorf("%s has no name", name)
+		}
+		if parsed, err := ParseKey(keyName); err != nil || parsed != key {
+			t.Errorf("%s: KeyName is %q which parses to %#x (%v)", name, keyName, parsed, err)
+		}
+		for _, n := range []string{name, strings.TrimPrefix(name, "Key"), strings.ToUpper(name)} {
+			if parsed, err := ParseKey(n); err != nil || parsed != key {
+				t.Errorf("ParseKey(%q) = %#x (%v) but want %#x", n, parsed, err, key)
+			}
+		}
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  uint16
+	}{
+		{"ctrl", KeyControl},
+		{" Esc ", KeyEscape},
+		{"PgDn", KeyPageDown},
+		{"0x22", 0x22},
+		{"0X07", 0x07},
+		{"0xfe", 0xFE},
+	}
+	for _, tt := range tests {
+		if key, err := ParseKey(tt.name); err != nil || key != tt.key {
+			t.Errorf("ParseKey(%q) = %#x (%v) but want %#x", tt.name, key, err, tt.key)
+		}
+	}
+	for _, name := range []string{"", "Key", "Nope", "0x", "0x00", "0xFF", "0x100", "0xZZ"} {
+		if key, err := ParseKey(name); err == nil {
+			t.Errorf("ParseKey(%q) = %#x but want an error", name, key)
+		}
+	}
+	if name := KeyName(0x07); name != "0x07" {
+		t.Errorf("want name 0x07 for a key without constant but have %q", name)
+	}
+}
+
+func TestParseChord(t *testing.T) {
+	keys, err := ParseChord("Ctrl + shift+KeyS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint16{KeyControl, KeyShift, KeyS}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("want %v but have %v", want, keys)
+	}
+
+	for _, chord := range []string{"", " ", "+", "Ctrl+", "+A", "Ctrl++", "Ctrl++A", "Ctrl+ +A", "Ctrl+Nope"} {
+		if keys, err := ParseChord(chord); err == nil {
+			t.Errorf("ParseChord(%q) = %v but want an error", chord, keys)
+		}
+	}
+}
+
+func TestTypeChordReleasesInReverseOrder(t *testing.T) {
+	d := useFakeDriver(t)
+	if err := TypeChord("Ctrl+Shift+A"); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]InputEvent{
+		{keyDown(KeyControl)},
+		{keyDown(KeyShift)},
+		{keyDown(KeyA)},
+		{keyUp(KeyA), keyUp(KeyShift), keyUp(KeyControl)},
+	}
+	if !reflect.DeepEqual(d.injected, want) {
+		t.Errorf("\ngot  %v\nwant %v", d.injected, want)
+	}
+}
+
+func TestTypeChordReleasesWhenPressingFails(t *testing.T) {
+	d := useFakeDriver(t)
+	d.failAt = 3
+	if err := TypeChord("Ctrl+Shift+A+B"); err == nil {
+		t.Error("want the error of pressing KeyA")
+	}
+	// Pressing KeyA failed but it might be down anyway, B is never pressed.
+	want := [][]InputEvent{
+		{keyDown(KeyControl)},
+		{keyDown(KeyShift)},
+		{keyUp(KeyA), keyUp(KeyShift), keyUp(KeyControl)},
+	}
+	if !reflect.DeepEqual(d.injected, want) {
+		t.Errorf("\ngot  %v\nwant %v", d.injected, want)
+	}
+	if keys, _ := Held(); len(keys) != 0 {
+		t.Errorf("keys %v are still held", keys)
+	}
+}
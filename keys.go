@@ -0,0 +1,204 @@
+package auto
+
+// Key... constants are keys you can pass to TypeKey, PressKey and ReleaseKey.
+// Their values are the Windows virtual key codes. On other systems they are
+// mapped to the corresponding keys.
+const (
+	KeyA                  = 'A'
+	KeyB                  = 'B'
+	KeyC                  = 'C'
+	KeyD                  = 'D'
+	KeyE                  = 'E'
+	KeyF                  = 'F'
+	KeyG                  = 'G'
+	KeyH                  = 'H'
+	KeyI                  = 'I'
+	KeyJ                  = 'J'
+	KeyK                  = 'K'
+	KeyL                  = 'L'
+	KeyM                  = 'M'
+	KeyN                  = 'N'
+	KeyO                  = 'O'
+	KeyP                  = 'P'
+	KeyQ                  = 'Q'
+	KeyR                  = 'R'
+	KeyS                  = 'S'
+	KeyT                  = 'T'
+	KeyU                  = 'U'
+	KeyV                  = 'V'
+	KeyW                  = 'W'
+	KeyX                  = 'X'
+	KeyY                  = 'Y'
+	KeyZ                  = 'Z'
+	Key0                  = '0'
+	Key1                  = '1'
+	Key2                  = '2'
+	Key3                  = '3'
+	Key4                  = '4'
+	Key5                  = '5'
+	Key6                  = '6'
+	Key7                  = '7'
+	Key8                  = '8'
+	Key9                  = '9'
+	KeyLeftButton         = 0x01
+	KeyRightButton        = 0x02
+	KeyMiddleButton       = 0x04
+	KeyXButton1           = 0x05
+	KeyXButton2           = 0x06
+	KeyCancel             = 0x03
+	KeyBackspace          = 0x08
+	KeyTab                = 0x09
+	KeyClear              = 0x0C
+	KeyEnter              = 0x0D
+	KeyShift              = 0x10
+	KeyControl            = 0x11
+	KeyAlt                = 0x12
+	KeyPause              = 0x13
+	KeyCapsLock           = 0x14
+	KeyImeKana            = 0x15
+	KeyImeHangul          = 0x15
+	KeyImeOn              = 0x16
+	KeyImeJunja           = 0x17
+	KeyImeFinal           = 0x18
+	KeyImeHanja           = 0x19
+	KeyImeKanji           = 0x19
+	KeyImeOff             = 0x1A
+	KeyEscape             = 0x1B
+	KeyImeConvert         = 0x1C
+	KeyImeNonConvert      = 0x1D
+	KeyImeAccept          = 0x1E
+	KeyImeModeChange      = 0x1F
+	KeySpace              = 0x20
+	KeyPageUp             = 0x21
+	KeyPageDown           = 0x22
+	KeyEnd                = 0x23
+	KeyHome               = 0x24
+	KeyLeft               = 0x25
+	KeyUp                 = 0x26
+	KeyRight              = 0x27
+	KeyDown               = 0x28
+	KeySelect             = 0x29
+	KeyPrint              = 0x2A
+	KeyExecute            = 0x2B
+	KeyPrintScreen        = 0x2C
+	KeyInsert             = 0x2D
+	KeyDelete             = 0x2E
+	KeyHelp               = 0x2F
+	KeyLeftWin            = 0x5B
+	KeyRightWin           = 0x5C
+	KeyApps               = 0x5D
+	KeySleep              = 0x5F
+	KeyNum0               = 0x60
+	KeyNum1               = 0x61
+	KeyNum2               = 0x62
+	KeyNum3               = 0x63
+	KeyNum4               = 0x64
+	KeyNum5               = 0x65
+	KeyNum6               = 0x66
+	KeyNum7               = 0x67
+	KeyNum8               = 0x68
+	KeyNum9               = 0x69
+	KeyMultiply           = 0x6A
+	KeyPlus               = 0x6B
+	KeySeparator          = 0x6C
+	KeyMinus              = 0x6D
+	KeyDecimal            = 0x6E
+	KeyDivide             = 0x6F
+	KeyF1                 = 0x70
+	KeyF2                 = 0x71
+	KeyF3                 = 0x72
+	KeyF4                 = 0x73
+	KeyF5                 = 0x74
+	KeyF6                 = 0x75
+	KeyF7                 = 0x76
+	KeyF8                 = 0x77
+	KeyF9                 = 0x78
+	KeyF10                = 0x79
+	KeyF11                = 0x7A
+	KeyF12                = 0x7B
+	KeyF13                = 0x7C
+	KeyF14                = 0x7D
+	KeyF15                = 0x7E
+	KeyF16                = 0x7F
+	KeyF17                = 0x80
+	KeyF18                = 0x81
+	KeyF19                = 0x82
+	KeyF20                = 0x83
+	KeyF21                = 0x84
+	KeyF22                = 0x85
+	KeyF23                = 0x86
+	KeyF24                = 0x87
+	KeyNumLock            = 0x90
+	KeyScrollLock         = 0x91
+	KeyOemNecEqual        = 0x92
+	KeyOemFjJisho         = 0x92
+	KeyOemFjMasshou       = 0x93
+	KeyOemFjTouroku       = 0x94
+	KeyOemFjLoya          = 0x95
+	KeyOemFjRoya          = 0x96
+	KeyLeftShift          = 0xA0
+	KeyRightShift         = 0xA1
+	KeyLeftControl        = 0xA2
+	KeyRightControl       = 0xA3
+	KeyLeftAlt            = 0xA4
+	KeyRightAlt           = 0xA5
+	KeyBrowserBack        = 0xA6
+	KeyBrowserForward     = 0xA7
+	KeyBrowserRefresh     = 0xA8
+	KeyBrowserStop        = 0xA9
+	KeyBrowserSearch      = 0xAA
+	KeyBrowserFavorites   = 0xAB
+	KeyBrowserHome        = 0xAC
+	KeyVolumeMute         = 0xAD
+	KeyVolumeDown         = 0xAE
+	KeyVolumeUp           = 0xAF
+	KeyMediaNextTrack     = 0xB0
+	KeyMediaPreviousTrack = 0xB1
+	KeyMediaStop          = 0xB2
+	KeyMediaPlayPause     = 0xB3
+	KeyLaunchMail         = 0xB4
+	KeyLaunchMediaSelect  = 0xB5
+	KeyLaunchApp1         = 0xB6
+	KeyLaunchApp2         = 0xB7
+	KeyOemPlus            = 0xBB
+	KeyOemComma           = 0xBC
+	KeyOemMinus           = 0xBD
+	KeyOemPeriod          = 0xBE
+	KeyOem1               = 0xBA
+	KeyOem2               = 0xBF
+	KeyOem3               = 0xC0
+	KeyOem4               = 0xDB
+	KeyOem5               = 0xDC
+	KeyOem6               = 0xDD
+	KeyOem7               = 0xDE
+	KeyOem8               = 0xDF
+	KeyOemAx              = 0xE1
+	KeyOem102             = 0xE2
+	KeyIcoHelp            = 0xE3
+	KeyIco00              = 0xE4
+	KeyImeProcessKey      = 0xE5
+	KeyIcoClear           = 0xE6
+	KeyUnicodePacket      = 0xE7
+	KeyOemReset           = 0xE9
+	KeyOemJump            = 0xEA
+	KeyOemPa1             = 0xEB
+	KeyOemPa2             = 0xEC
+	KeyOemPa3             = 0xED
+	KeyOemWsControl       = 0xEE
+	KeyOemCuSel           = 0xEF
+	KeyOemAttn            = 0xF0
+	KeyOemFinish          = 0xF1
+	KeyOemCopy            = 0xF2
+	KeyOemAuto            = 0xF3
+	KeyOemEnlw            = 0xF4
+	KeyOemNBackTab        = 0xF5
+	KeyAttn               = 0xF6
+	KeyCrSel              = 0xF7
+	KeyExSel              = 0xF8
+	KeyErEof              = 0xF9
+	KeyPlay               = 0xFA
+	KeyZoom               = 0xFB
+	KeyNoName             = 0xFC
+	KeyPa1                = 0xFD
+	KeyOemClear           = 0xFE
+)
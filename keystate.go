@@ -0,0 +1,75 @@
+package auto
+
+import "fmt"
+
+// IsKeyDown reports whether the given key is held down right now, see the
+// Key... constants. This includes the mouse buttons, e.g. KeyLeftButton. The
+// generic modifier keys KeyShift, KeyControl and KeyAlt are down if their left
+// or right key is down.
+//
+// On X11 the state of KeyXButton1 and KeyXButton2 is not available.
+func IsKeyDown(key uint16) (bool, error) {
+	return driver().IsKeyDown(key)
+}
+
+// IsKeyToggled reports whether the given lock key is on. key must be
+// KeyCapsLock, KeyNumLock or KeyScrollLock.
+func IsKeyToggled(key uint16) (bool, error) {
+	if !isLockKey(key) {
+		return false, fmt.Errorf("key %s is not a lock key", KeyName(key))
+	}
+	return driver().IsKeyToggled(key)
+}
+
+// SetLockKey turns the given lock key on or off, by typing it if it is not
+// in the right state already. key must be KeyCapsLock, KeyNumLock or
+// KeyScrollLock.
+func SetLockKey(key uint16, on bool) error {
+	toggled, err := IsKeyToggled(key)
+	if err != nil {
+		return err
+	}
+	if toggled == on {
+		return nil
+	}
+	return TypeKey(key)
+}
+
+func isLockKey(key uint16) bool {
+	return key == KeyCapsLock || key == KeyNumLock || key == KeyScrollLock
+}
+
+// modifierKeys are the keys that ModifiersDown checks.
+var modifierKeys = []uint16{KeyShift, KeyControl, KeyAlt, KeyLeftWin, KeyRightWin}
+
+// ModifiersDown returns the modifier keys that are held down right now, in
+// this order: KeyShift, KeyControl, KeyAlt, KeyLeftWin, KeyRightWin. Shift,
+// Control and Alt are reported as the generic keys, no matter which side is
+// down.
+func ModifiersDown() ([]uint16, error) {
+	var down []uint16
+	for _, key := range modifierKeys {
+		isDown, err := IsKeyDown(key)
+		if err != nil {
+			return nil, err
+		}
+		if isDown {
+			down = append(down, key)
+		}
+	}
+	return down, nil
+}
+
+// keySides are the left and right keys of the generic modifier keys.
+var keySides = map[uint16][2]uint16{
+	KeyShift:   {KeyLeftShift, KeyRightShift},
+	KeyControl: {KeyLeftControl, KeyRightControl},
+	KeyAlt:     {KeyLeftAlt, KeyRightAlt},
+}
+
+// isKey reports whether the physical key pressed is the given key or one of
+// its sides, if key is a generic modifier key.
+func isKey(pressed, key uint16) bool {
+	sides, ok := keySides[key]
+	return pressed == key || ok && (pressed == sides[0] || pressed == sides[1])
+}
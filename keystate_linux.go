@@ -0,0 +1,146 @@
+package auto
+
+import (
+	"errors"
+	"fmt"
+)
+
+func (linuxDriver) IsKeyDown(key uint16) (bool, error) {
+	c, err := display()
+	if err != nil {
+		return false, err
+	}
+
+	if button, ok := keyButtons[key]; ok {
+		if button > xButtonRight {
+			return false, fmt.Errorf("X11 does not report the state of %s", KeyName(key))
+		}
+		mask, err := c.pointerMask()
+		if err != nil {
+			return false, err
+		}
+		// Button1Mask is 0x100, the other buttons follow.
+		return mask&(0x80<<button) != 0, nil
+	}
+
+	mapping, err := c.keyboardMapping()
+	if err != nil {
+		return false, err
+	}
+	const queryKeymap = 44
+	reply, err := c.roundTrip(newXRequest(queryKeymap, 0))
+	if err != nil {
+		return false, err
+	}
+	// The reply has a bit for each key code that is down.
+	keys := reply[8:40]
+	for code := int(c.minKeycode); code <= int(c.maxKeycode); code++ {
+		if keys[code/8]&(1<<(code%8)) == 0 {
+			continue
+		}
+		if pressed, ok := symKeys[mapping.keySym(byte(code))]; ok && isKey(pressed, key) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// pointerMask returns the state of the mouse buttons and modifiers.
+func (c *xConn) pointerMask() (uint16, error) {
+	const queryPointer = 38
+	reply, err := c.roundTrip(newXRequest(queryPointer, 0).u32(c.screen.root))
+	if err != nil {
+		return 0, err
+	}
+	return le.Uint16(reply[24:]), nil
+}
+
+// lockKeySyms are the key symbols of the lock keys.
+var lockKeySyms = map[uint16]uint32{
+	KeyCapsLock:   0xFFE5, // Caps_Lock
+	KeyNumLock:    0xFF7F, // Num_Lock
+	KeyScrollLock: 0xFF14, // Scroll_Lock
+}
+
+func (linuxDriver) IsKeyToggled(key uint16) (bool, error) {
+	c, err := display()
+	if err != nil {
+		return false, err
+	}
+
+	// Caps Lock and Num Lock lock a modifier. We look up which one in the
+	// modifier mapping and ask XKB whether it is locked.
+	mod, ok, err := c.lockModifier(lockKeySyms[key])
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		locked, err := c.xkbLockedMods()
+		if err != nil {
+			return false, err
+		}
+		return locked&(1<<mod) != 0, nil
+	}
+
+	// Scroll Lock usually has no modifier, only its keyboard LED, which is
+	// the third one.
+	led := map[uint16]uint{KeyCapsLock: 1, KeyNumLock: 2, KeyScrollLock: 3}[key]
+	const getKeyboardControl = 103
+	reply, err := c.roundTrip(newXRequest(getKeyboardControl, 0))
+	if err != nil {
+		return false, err
+	}
+	return le.Uint32(reply[8:])&(1<<(led-1)) != 0, nil
+}
+
+// lockModifier returns the index of the modifier, from 0 (Shift) to 7 (Mod5),
+// that a key with the given key symbol is mapped to.
+func (c *xConn) lockModifier(sym uint32) (mod uint, ok bool, err error) {
+	mapping, err := c.keyboardMapping()
+	if err != nil {
+		return 0, false, err
+	}
+	const getModifierMapping = 119
+	reply, err := c.roundTrip(newXRequest(getModifierMapping, 0))
+	if err != nil {
+		return 0, false, err
+	}
+	perModifier := int(reply[1])
+	codes := reply[32:]
+	for i := 0; i < 8*perModifier && i < len(codes); i++ {
+		if codes[i] != 0 && mapping.keySym(codes[i]) == sym {
+			return uint(i / perModifier), true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// xkbLockedMods returns the modifiers that are locked on the core keyboard.
+func (c *xConn) xkbLockedMods() (byte, error) {
+	xkb, err := c.extension("XKEYBOARD")
+	if err != nil {
+		return 0, err
+	}
+
+	// Clients have to announce the XKB version that they use before they can
+	// make any other XKB requests.
+	const useExtension = 0
+	reply, err := c.roundTrip(newXRequest(xkb.opcode, useExtension).u16(1).u16(0))
+	if err != nil {
+		return 0, err
+	}
+	if reply[1] == 0 {
+		return 0, errors.New("the X server does not support XKB version 1.0")
+	}
+
+	const (
+		getState     = 4
+		useCoreKbd   = 0x100
+		lockedModsAt = 11
+	)
+	reply, err = c.roundTrip(newXRequest(xkb.opcode, getState).u16(useCoreKbd).pad(2))
+	if err != nil {
+		return 0, err
+	}
+	return reply[lockedModsAt], nil
+}
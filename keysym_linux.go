@@ -0,0 +1,240 @@
+package auto
+
+// keySyms maps the Key... constants to X11 key symbols. Keys that are missing
+// here have no X11 equivalent.
+var keySyms = map[uint16]uint32{
+	KeyA:                  'a',
+	KeyB:                  'b',
+	KeyC:                  'c',
+	KeyD:                  'd',
+	KeyE:                  'e',
+	KeyF:                  'f',
+	KeyG:                  'g',
+	KeyH:                  'h',
+	KeyI:                  'i',
+	KeyJ:                  'j',
+	KeyK:                  'k',
+	KeyL:                  'l',
+	KeyM:                  'm',
+	KeyN:                  'n',
+	KeyO:                  'o',
+	KeyP:                  'p',
+	KeyQ:                  'q',
+	KeyR:                  'r',
+	KeyS:                  's',
+	KeyT:                  't',
+	KeyU:                  'u',
+	KeyV:                  'v',
+	KeyW:                  'w',
+	KeyX:                  'x',
+	KeyY:                  'y',
+	KeyZ:                  'z',
+	Key0:                  '0',
+	Key1:                  '1',
+	Key2:                  '2',
+	Key3:                  '3',
+	Key4:                  '4',
+	Key5:                  '5',
+	Key6:                  '6',
+	Key7:                  '7',
+	Key8:                  '8',
+	Key9:                  '9',
+	KeyCancel:             0xFF69, // Cancel
+	KeyBackspace:          0xFF08, // BackSpace
+	KeyTab:                0xFF09, // Tab
+	KeyClear:              0xFF0B, // Clear
+	KeyEnter:              0xFF0D, // Return
+	KeyShift:              0xFFE1, // Shift_L
+	KeyControl:            0xFFE3, // Control_L
+	KeyAlt:                0xFFE9, // Alt_L
+	KeyPause:              0xFF13, // Pause
+	KeyCapsLock:           0xFFE5, // Caps_Lock
+	KeyImeHangul:          0xFF31, // Hangul, same as KeyImeKana
+	KeyImeJunja:           0xFF38, // Hangul_Jeonja
+	KeyImeKanji:           0xFF21, // Kanji, same as KeyImeHanja
+	KeyEscape:             0xFF1B, // Escape
+	KeyImeConvert:         0xFF23, // Henkan
+	KeyImeNonConvert:      0xFF22, // Muhenkan
+	KeyImeModeChange:      0xFF7E, // Mode_switch
+	KeySpace:              ' ',
+	KeyPageUp:             0xFF55,     // Prior
+	KeyPageDown:           0xFF56,     // Next
+	KeyEnd:                0xFF57,     // End
+	KeyHome:               0xFF50,     // Home
+	KeyLeft:               0xFF51,     // Left
+	KeyUp:                 0xFF52,     // Up
+	KeyRight:              0xFF53,     // Right
+	KeyDown:               0xFF54,     // Down
+	KeySelect:             0xFF60,     // Select
+	KeyPrint:              0xFF61,     // Print
+	KeyExecute:            0xFF62,     // Execute
+	KeyPrintScreen:        0xFF61,     // Print
+	KeyInsert:             0xFF63,     // Insert
+	KeyDelete:             0xFFFF,     // Delete
+	KeyHelp:               0xFF6A,     // Help
+	KeyLeftWin:            0xFFEB,     // Super_L
+	KeyRightWin:           0xFFEC,     // Super_R
+	KeyApps:               0xFF67,     // Menu
+	KeySleep:              0x1008FF2F, // XF86Sleep
+	KeyNum0:               0xFFB0,     // KP_0
+	KeyNum1:               0xFFB1,     // KP_1
+	KeyNum2:               0xFFB2,     // KP_2
+	KeyNum3:               0xFFB3,     // KP_3
+	KeyNum4:               0xFFB4,     // KP_4
+	KeyNum5:               0xFFB5,     // KP_5
+	KeyNum6:               0xFFB6,     // KP_6
+	KeyNum7:               0xFFB7,     // KP_7
+	KeyNum8:               0xFFB8,     // KP_8
+	KeyNum9:               0xFFB9,     // KP_9
+	KeyMultiply:           0xFFAA,     // KP_Multiply
+	KeyPlus:               0xFFAB,     // KP_Add
+	KeySeparator:          0xFFAC,     // KP_Separator
+	KeyMinus:              0xFFAD,     // KP_Subtract
+	KeyDecimal:            0xFFAE,     // KP_Decimal
+	KeyDivide:             0xFFAF,     // KP_Divide
+	KeyF1:                 0xFFBE,     // F1
+	KeyF2:                 0xFFBF,     // F2
+	KeyF3:                 0xFFC0,     // F3
+	KeyF4:                 0xFFC1,     // F4
+	KeyF5:                 0xFFC2,     // F5
+	KeyF6:                 0xFFC3,     // F6
+	KeyF7:                 0xFFC4,     // F7
+	KeyF8:                 0xFFC5,     // F8
+	KeyF9:                 0xFFC6,     // F9
+	KeyF10:                0xFFC7,     // F10
+	KeyF11:                0xFFC8,     // F11
+	KeyF12:                0xFFC9,     // F12
+	KeyF13:                0xFFCA,     // F13
+	KeyF14:                0xFFCB,     // F14
+	KeyF15:                0xFFCC,     // F15
+	KeyF16:                0xFFCD,     // F16
+	KeyF17:                0xFFCE,     // F17
+	KeyF18:                0xFFCF,     // F18
+	KeyF19:                0xFFD0,     // F19
+	KeyF20:                0xFFD1,     // F20
+	KeyF21:                0xFFD2,     // F21
+	KeyF22:                0xFFD3,     // F22
+	KeyF23:                0xFFD4,     // F23
+	KeyF24:                0xFFD5,     // F24
+	KeyNumLock:            0xFF7F,     // Num_Lock
+	KeyScrollLock:         0xFF14,     // Scroll_Lock
+	KeyOemNecEqual:        0xFFBD,     // KP_Equal, same as KeyOemFjJisho
+	KeyOemFjMasshou:       0xFF2C,     // Massyo
+	KeyOemFjTouroku:       0xFF2B,     // Touroku
+	KeyLeftShift:          0xFFE1,     // Shift_L
+	KeyRightShift:         0xFFE2,     // Shift_R
+	KeyLeftControl:        0xFFE3,     // Control_L
+	KeyRightControl:       0xFFE4,     // Control_R
+	KeyLeftAlt:            0xFFE9,     // Alt_L
+	KeyRightAlt:           0xFFEA,     // Alt_R
+	KeyBrowserBack:        0x1008FF26, // XF86Back
+	KeyBrowserForward:     0x1008FF27, // XF86Forward
+	KeyBrowserRefresh:     0x1008FF29, // XF86Refresh
+	KeyBrowserStop:        0x1008FF28, // XF86Stop
+	KeyBrowserSearch:      0x1008FF1B, // XF86Search
+	KeyBrowserFavorites:   0x1008FF30, // XF86Favorites
+	KeyBrowserHome:        0x1008FF18, // XF86HomePage
+	KeyVolumeMute:         0x1008FF12, // XF86AudioMute
+	KeyVolumeDown:         0x1008FF11, // XF86AudioLowerVolume
+	KeyVolumeUp:           0x1008FF13, // XF86AudioRaiseVolume
+	KeyMediaNextTrack:     0x1008FF17, // XF86AudioNext
+	KeyMediaPreviousTrack: 0x1008FF16, // XF86AudioPrev
+	KeyMediaStop:          0x1008FF15, // XF86AudioStop
+	KeyMediaPlayPause:     0x1008FF14, // XF86AudioPlay
+	KeyLaunchMail:         0x1008FF19, // XF86Mail
+	KeyLaunchMediaSelect:  0x1008FF32, // XF86AudioMedia
+	KeyLaunchApp1:         0x1008FF33, // XF86MyComputer
+	KeyLaunchApp2:         0x1008FF1D, // XF86Calculator
+	KeyOemPlus:            '=',
+	KeyOemComma:           ',',
+	KeyOemMinus:           '-',
+	KeyOemPeriod:          '.',
+	KeyOem1:               ';',
+	KeyOem2:               '/',
+	KeyOem3:               '`',
+	KeyOem4:               '[',
+	KeyOem5:               '\\',
+	KeyOem6:               ']',
+	KeyOem7:               '\'',
+	KeyOem102:             '<',
+	KeyAttn:               0xFD0E, // 3270_Attn
+	KeyCrSel:              0xFD1C, // 3270_CursorSelect
+	KeyExSel:              0xFD1D, // 3270_ExSelect
+	KeyErEof:              0xFD06, // 3270_EraseEOF
+	KeyPlay:               0xFD16, // 3270_Play
+	KeyPa1:                0xFD0A, // 3270_PA1
+	KeyOemClear:           0xFF0B, // Clear
+}
+
+// symKeys is the inverse of keySyms. Where several keys share a key symbol, it
+// uses the key that Windows reports for the physical key, e.g. KeyLeftShift
+// for Shift_L. It also maps the keypad symbols that are active while Num Lock
+// is off and the AltGr symbol.
+var symKeys = func() map[uint32]uint16 {
+	m := make(map[uint32]uint16)
+	for key, sym := range keySyms {
+		m[sym] = key
+	}
+	m[0xFFE1] = KeyLeftShift
+	m[0xFFE3] = KeyLeftControl
+	m[0xFFE9] = KeyLeftAlt
+	m[0xFF61] = KeyPrintScreen
+	m[0xFF0B] = KeyClear
+	m[0xFE03] = KeyRightAlt // ISO_Level3_Shift
+	m[0xFF8D] = KeyEnter    // KP_Enter
+	m[0xFF95] = KeyHome     // KP_Home
+	m[0xFF96] = KeyLeft     // KP_Left
+	m[0xFF97] = KeyUp       // KP_Up
+	m[0xFF98] = KeyRight    // KP_Right
+	m[0xFF99] = KeyDown     // KP_Down
+	m[0xFF9A] = KeyPageUp   // KP_Prior
+	m[0xFF9B] = KeyPageDown // KP_Next
+	m[0xFF9C] = KeyEnd      // KP_End
+	m[0xFF9D] = KeyClear    // KP_Begin
+	m[0xFF9E] = KeyInsert   // KP_Insert
+	m[0xFF9F] = KeyDelete   // KP_Delete
+	return m
+}()
+
+// keyButtons maps the mouse button Key... constants to X11 pointer buttons.
+var keyButtons = map[uint16]byte{
+	KeyLeftButton:   xButtonLeft,
+	KeyRightButton:  xButtonRight,
+	KeyMiddleButton: xButtonMiddle,
+	KeyXButton1:     xButtonBack,
+	KeyXButton2:     xButtonForward,
+}
+
+// These key symbols are used when typing text.
+const (
+	xkShiftL    = 0xFFE1
+	xkReturn    = 0xFF0D
+	xkBackSpace = 0xFF08
+	xkTab       = 0xFF09
+)
+
+// runeToKeySym returns the X11 key symbol for the given character. Latin-1
+// characters have their own code points as key symbols, all other Unicode
+// characters are offset by 0x01000000.
+func runeToKeySym(r rune) uint32 {
+	if 0x20 <= r && r <= 0x7E || 0xA0 <= r && r <= 0xFF {
+		return uint32(r)
+	}
+	return 0x01000000 | uint32(r)
+}
+
+// keySymToRune is the inverse of runeToKeySym. It also handles some legacy key
+// symbols that keyboard layouts commonly use instead of the Unicode ones. It
+// returns -1 for key symbols that do not represent characters.
+func keySymToRune(sym uint32) rune {
+	if 0x20 <= sym && sym <= 0x7E || 0xA0 <= sym && sym <= 0xFF {
+		return rune(sym)
+	}
+	if sym&0xFF000000 == 0x01000000 {
+		return rune(sym & 0x00FFFFFF)
+	}
+	if sym == 0x20AC { // EuroSign
+		return '€'
+	}
+	return -1
+}
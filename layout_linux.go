@@ -0,0 +1,125 @@
+package auto
+
+import (
+	"fmt"
+	"unicode"
+)
+
+func (linuxDriver) KeysForRune(r rune) ([]InputEvent, error) {
+	i, err := currentInjector()
+	if err != nil {
+		return nil, err
+	}
+	return i.keysForRune(r)
+}
+
+func (xtestInjector) keysForRune(r rune) ([]InputEvent, error) {
+	c, err := display()
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := c.keyboardMapping()
+	if err != nil {
+		return nil, err
+	}
+	if keys, ok := mapping.runeKeys(r); ok {
+		return chordEvents(keys...), nil
+	}
+	if _, _, ok := mapping.findRune(runeToKeySym(r)); ok {
+		return nil, fmt.Errorf("cannot type %q, its key on the layout has no Key... constant: %w", r, ErrNotOnLayout)
+	}
+	return nil, fmt.Errorf("cannot type %q: %w", r, ErrNotOnLayout)
+}
+
+const xkISOLevel3Shift = 0xFE03
+
+// layoutColumns are the columns of the core keyboard mapping that we can type,
+// with the modifiers that select them. X servers with XKB put the third and
+// fourth level of the first group, which AltGr selects, in columns 4 and 5.
+var layoutColumns = []struct {
+	column    int
+	modifiers []uint16
+}{
+	{0, nil},
+	{1, []uint16{KeyShift}},
+	{4, []uint16{KeyRightAlt}},
+	{5, []uint16{KeyShift, KeyRightAlt}},
+}
+
+// runeKeys returns the keys that produce r, modifiers first. Only key codes
+// that keyEvent generates for one of the Key... constants can be used, so the
+// character's key must produce that constant's key symbol without modifiers.
+// This rules out dead keys and keys like the ö on a German layout, their key
+// symbols have no Key... constant.
+func (m xKeyboardMapping) runeKeys(r rune) ([]uint16, bool) {
+	lower := unicode.ToLower(r)
+	altGr := m.hasAltGr()
+	for _, col := range layoutColumns {
+		if col.column >= m.perKeycode || col.column >= 4 && !altGr {
+			continue
+		}
+		for i := col.column; i < len(m.keySyms); i += m.perKeycode {
+			produces := keySymToRune(m.keySyms[i]) == r
+			// Keys that only list a lower case letter produce the upper case
+			// letter with Shift.
+			if col.column == 1 && lower != r && m.keySyms[i] == 0 && keySymToRune(m.keySyms[i-1]) == lower {
+				produces = true
+			}
+			if !produces {
+				continue
+			}
+			if key, ok := m.codeKey(m.minKeycode + byte(i/m.perKeycode)); ok {
+				return append(append([]uint16(nil), col.modifiers...), key), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// codeKey returns the Key... constant for which keyEvent presses the given key
+// code.
+func (m xKeyboardMapping) codeKey(code byte) (uint16, bool) {
+	key, ok := symKeys[m.keySym(code)]
+	if !ok {
+		return 0, false
+	}
+	found, column, ok := m.find(keySyms[key])
+	return key, ok && found == code && column == 0
+}
+
+// hasAltGr reports whether keyEvent presses AltGr, which is ISO_Level3_Shift,
+// for KeyRightAlt.
+func (m xKeyboardMapping) hasAltGr() bool {
+	code, _, ok := m.find(keySyms[KeyRightAlt])
+	if !ok {
+		code, _, ok = m.find(xkISOLevel3Shift)
+	}
+	return ok && m.keySym(code) == xkISOLevel3Shift
+}
+
+func (u *uinputInjector) keysForRune(r rune) ([]InputEvent, error) {
+	k, ok := usLayout[r]
+	if !ok {
+		return nil, fmt.Errorf("cannot type %q, without an X server only the characters on a US keyboard can be typed: %w", r, ErrNotOnLayout)
+	}
+	key, ok := evdevKey(k.code)
+	if !ok {
+		return nil, fmt.Errorf("cannot type %q: %w", r, ErrNotOnLayout)
+	}
+	if k.shift {
+		return chordEvents(KeyShift, key), nil
+	}
+	return chordEvents(key), nil
+}
+
+// evdevKey returns the Key... constant for the given Linux key code. If several
+// constants share the code, it returns the smallest one.
+func evdevKey(code uint16) (uint16, bool) {
+	var key uint16
+	for k, c := range evdevKeys {
+		if c == code && (key == 0 || k < key) {
+			key = k
+		}
+	}
+	return key, key != 0
+}
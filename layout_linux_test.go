@@ -0,0 +1,93 @@
+package auto
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testKeyboardMapping builds a keyboard mapping with 7 key symbols per key
+// code, like X servers with XKB report it. Columns 4 and 5 are the AltGr
+// levels.
+func testKeyboardMapping(keys map[byte][]uint32) xKeyboardMapping {
+	const minKeycode, perKeycode = 8, 7
+	m := xKeyboardMapping{
+		minKeycode: minKeycode,
+		perKeycode: perKeycode,
+		keySyms:    make([]uint32, (256-minKeycode)*perKeycode),
+	}
+	for code, syms := range keys {
+		copy(m.keySyms[(int(code)-minKeycode)*perKeycode:], syms)
+	}
+	return m
+}
+
+// germanKeys are some keys of a German layout, with their evdev key codes.
+var germanKeys = map[byte][]uint32{
+	10:  {'1', '!'},
+	24:  {'q', 'Q', 'q', 'Q', '@', 0x7D9}, // @ and Greek Omega with AltGr
+	38:  {'a', 'A'},
+	47:  {0xF6, 0xD6},       // odiaeresis, Odiaeresis
+	49:  {0xFE52, 0xB0},     // dead_circumflex, degree
+	50:  {0xFFE1},           // Shift_L
+	56:  {'b'},              // only the lower case letter
+	108: {xkISOLevel3Shift}, // the right Alt key is AltGr
+}
+
+func TestRuneKeys(t *testing.T) {
+	m := testKeyboardMapping(germanKeys)
+	tests := []struct {
+		r    rune
+		keys []uint16
+	}{
+		{'a', []uint16{KeyA}},
+		{'A', []uint16{KeyShift, KeyA}},
+		{'!', []uint16{KeyShift, Key1}},
+		{'B', []uint16{KeyShift, KeyB}},
+		{'@', []uint16{KeyRightAlt, KeyQ}},
+	}
+	for _, tt := range tests {
+		keys, ok := m.runeKeys(tt.r)
+		if !ok || !reflect.DeepEqual(keys, tt.keys) {
+			t.Errorf("%q: want %v but have %v (%v)", tt.r, tt.keys, keys, ok)
+		}
+	}
+
+	// ö has no Key... constant, ^ and ê need a dead key and € is not on the
+	// layout.
+	for _, r := range "öÖ^ê°€" {
+		if keys, ok := m.runeKeys(r); ok {
+			t.Errorf("%q: want no keys but have %v", r, keys)
+		}
+	}
+}
+
+func TestRuneKeysWithoutAltGr(t *testing.T) {
+	keys := map[byte][]uint32{}
+	for code, syms := range germanKeys {
+		keys[code] = syms
+	}
+	keys[108] = []uint32{0xFFEA} // Alt_R
+	m := testKeyboardMapping(keys)
+
+	if m.hasAltGr() {
+		t.Error("the right Alt key is not AltGr")
+	}
+	if keys, ok := m.runeKeys('@'); ok {
+		t.Errorf("'@' needs AltGr but have keys %v", keys)
+	}
+}
+
+func TestUinputKeysForRune(t *testing.T) {
+	var u uinputInjector
+	events, err := u.keysForRune('A')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := chordEvents(KeyShift, KeyA); !reflect.DeepEqual(events, want) {
+		t.Errorf("\ngot  %v\nwant %v", events, want)
+	}
+	if _, err := u.keysForRune('ö'); !errors.Is(err, ErrNotOnLayout) {
+		t.Errorf("want ErrNotOnLayout for 'ö' but got %v", err)
+	}
+}
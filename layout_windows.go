@@ -0,0 +1,208 @@
+package auto
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"github.com/gonutz/w32/v2"
+)
+
+var (
+	getKeyboardLayout = user32.NewProc("GetKeyboardLayout")
+	vkKeyScanEx       = user32.NewProc("VkKeyScanExW")
+	toUnicodeEx       = user32.NewProc("ToUnicodeEx")
+)
+
+// layoutKey is a virtual key and the modifiers that are held down while it is
+// pressed, like VkKeyScanEx reports them: 1 for Shift, 2 for Ctrl and 4 for
+// Alt.
+type layoutKey struct {
+	vk    uint16
+	state byte
+}
+
+// keys returns the modifier keys and the key itself, in the order in which
+// they are pressed.
+func (k layoutKey) keys() []uint16 {
+	var keys []uint16
+	if k.state&1 != 0 {
+		keys = append(keys, KeyShift)
+	}
+	if k.state&2 != 0 {
+		keys = append(keys, KeyControl)
+	}
+	if k.state&4 != 0 {
+		keys = append(keys, KeyAlt)
+	}
+	return append(keys, k.vk)
+}
+
+func (windowsDriver) KeysForRune(r rune) ([]InputEvent, error) {
+	if r <= 0xFFFF {
+		if keys, ok := runeKeys(foregroundLayout(), uint16(r)); ok {
+			var events []InputEvent
+			for _, k := range keys {
+				events = append(events, chordEvents(k.keys()...)...)
+			}
+			return events, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot type %q: %w", r, ErrNotOnLayout)
+}
+
+// foregroundLayout returns the keyboard layout of the foreground window, which
+// is the one that interprets our input.
+func foregroundLayout() w32.HKL {
+	thread, _ := w32.GetWindowThreadProcessId(w32.GetForegroundWindow())
+	layout, _, _ := getKeyboardLayout.Call(uintptr(thread))
+	return w32.HKL(layout)
+}
+
+// runeKeys returns the keys that produce the given character on the layout.
+// This is either a single key or a dead key followed by a base key.
+func runeKeys(layout w32.HKL, char uint16) ([]layoutKey, bool) {
+	ret, _, _ := vkKeyScanEx.Call(uintptr(char), uintptr(layout))
+	if int16(ret) != -1 {
+		// The high byte has the modifiers. Higher bits than Shift, Ctrl and
+		// Alt are for special keys that we cannot press.
+		k := layoutKey{vk: uint16(byte(ret)), state: byte(ret >> 8)}
+		if k.state&^7 == 0 {
+			text, dead := toUnicode(layout, k)
+			if dead {
+				clearDeadKey(layout, k)
+			}
+			// For accents, VkKeyScanEx returns their dead key, which does not
+			// produce anything on its own.
+			if len(text) == 1 && text[0] == char {
+				return []layoutKey{k}, true
+			}
+		}
+	}
+	if keys, ok := deadKeyChars(layout)[char]; ok {
+		return keys[:], true
+	}
+	return nil, false
+}
+
+// layoutStates are the modifier states in which we look for dead keys and
+// their base keys: none, Shift, AltGr and Shift+AltGr. Windows treats Ctrl+Alt
+// as AltGr.
+var layoutStates = []byte{0, 1, 6, 7}
+
+// layoutKeys returns all keys that might produce characters, in all
+// layoutStates.
+func layoutKeys() []layoutKey {
+	var keys []layoutKey
+	for _, state := range layoutStates {
+		for vk := uint16(KeyBackspace); vk <= 0xFE; vk++ {
+			switch vk {
+			case KeyShift, KeyControl, KeyAlt,
+				KeyLeftShift, KeyRightShift,
+				KeyLeftControl, KeyRightControl,
+				KeyLeftAlt, KeyRightAlt,
+				KeyUnicodePacket:
+				continue
+			}
+			keys = append(keys, layoutKey{vk: vk, state: state})
+		}
+	}
+	return keys
+}
+
+// deadKeyCache holds the characters that dead keys produce on the last layout
+// that we looked at. Finding them takes thousands of calls to ToUnicodeEx.
+var deadKeyCache struct {
+	sync.Mutex
+	layout w32.HKL
+	chars  map[uint16][2]layoutKey
+}
+
+// deadKeyChars returns the characters that a dead key followed by a base key
+// produce on the given layout, and these two keys.
+func deadKeyChars(layout w32.HKL) map[uint16][2]layoutKey {
+	deadKeyCache.Lock()
+	defer deadKeyCache.Unlock()
+
+	if deadKeyCache.chars != nil && deadKeyCache.layout == layout {
+		return deadKeyCache.chars
+	}
+
+	keys := layoutKeys()
+	var deadKeys []layoutKey
+	for _, k := range keys {
+		if _, dead := toUnicode(layout, k); dead {
+			clearDeadKey(layout, k)
+			deadKeys = append(deadKeys, k)
+		}
+	}
+
+	chars := make(map[uint16][2]layoutKey)
+	for _, dead := range deadKeys {
+		for _, base := range keys {
+			toUnicode(layout, dead)
+			text, isDead := toUnicode(layout, base)
+			if isDead {
+				clearDeadKey(layout, base)
+				continue
+			}
+			// Keys that do not combine with the dead key produce the accent
+			// and the base character. We prefer the first and thus simplest
+			// combination for each character.
+			if len(text) == 1 {
+				if _, ok := chars[text[0]]; !ok {
+					chars[text[0]] = [2]layoutKey{dead, base}
+				}
+			}
+		}
+	}
+
+	deadKeyCache.layout = layout
+	deadKeyCache.chars = chars
+	return chars
+}
+
+// toUnicode returns the text that pressing the given key produces on the
+// layout. dead is true for dead keys, which do not produce anything but change
+// what the next key produces.
+func toUnicode(layout w32.HKL, k layoutKey) (text []uint16, dead bool) {
+	var keyState [256]byte
+	if k.state&1 != 0 {
+		keyState[w32.VK_SHIFT] = 0x80
+	}
+	if k.state&2 != 0 {
+		keyState[w32.VK_CONTROL] = 0x80
+	}
+	if k.state&4 != 0 {
+		keyState[w32.VK_MENU] = 0x80
+	}
+	scan := w32.MapVirtualKeyEx(uint(k.vk), w32.MAPVK_VK_TO_VSC, layout)
+	var buf [8]uint16
+	ret, _, _ := toUnicodeEx.Call(
+		uintptr(k.vk),
+		uintptr(scan),
+		uintptr(unsafe.Pointer(&keyState[0])),
+		uintptr(unsafe.Pointer(&buf[0])),
+		uintptr(len(buf)),
+		0,
+		uintptr(layout),
+	)
+	n := int32(uint32(ret))
+	if n < 0 {
+		return nil, true
+	}
+	if int(n) > len(buf) {
+		n = int32(len(buf))
+	}
+	return buf[:n], false
+}
+
+// clearDeadKey resets the layout after the given dead key was pressed. Pressing
+// a dead key twice produces its accent and ends the dead key state.
+func clearDeadKey(layout w32.HKL, k layoutKey) {
+	for i := 0; i < 4; i++ {
+		if _, dead := toUnicode(layout, k); !dead {
+			return
+		}
+	}
+}
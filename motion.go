@@ -0,0 +1,207 @@
+package auto
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// MouseMotion describes the path that MoveMouseSmoothlyTo moves the mouse
+// along. The zero value moves the mouse in a straight line, accelerating at
+// the start and slowing down at the end (EaseInOut), in steps of 10ms.
+//
+// To make the movement look human, bend the path and add some noise, e.g.
+//
+//	auto.MouseMotion{Curvature: 0.2, Jitter: 1, Overshoot: 0.05}
+type MouseMotion struct {
+	// Easing maps the elapsed time, from 0 to 1, to the progress along the
+	// path, also from 0 to 1. It defaults to EaseInOut.
+	Easing Easing
+	// Curvature bends the path into a cubic Bezier curve. Its control points
+	// are moved sideways by up to Curvature times the distance to the target.
+	// 0 means a straight line, 0.2 is a gentle curve.
+	Curvature float64
+	// Jitter is the maximum number of pixels that each intermediate position
+	// is randomly displaced by. The final position is never displaced.
+	Jitter float64
+	// Overshoot is the distance, relative to the whole distance, that the
+	// mouse moves past the target before it comes back to it. 0.05 means the
+	// mouse overshoots by 5% of the distance.
+	Overshoot float64
+	// StepInterval is the time between two mouse moves. It defaults to 10ms.
+	StepInterval time.Duration
+	// Rand is the source for the randomness in Curvature and Jitter. Seed it
+	// to make paths reproducible, e.g. in tests. If it is nil, a source seeded
+	// with the current time is used. A rand.Rand must not be used from
+	// multiple goroutines at the same time.
+	Rand *rand.Rand
+}
+
+// Easing maps the elapsed time t from 0 to 1 to the progress from 0 to 1. It
+// must return 0 for t = 0 and 1 for t = 1.
+type Easing func(t float64) float64
+
+// EaseLinear moves at constant speed.
+func EaseLinear(t float64) float64 {
+	return t
+}
+
+// EaseIn starts slow and accelerates.
+func EaseIn(t float64) float64 {
+	return t * t * t
+}
+
+// EaseOut starts fast and decelerates.
+func EaseOut(t float64) float64 {
+	t = 1 - t
+	return 1 - t*t*t
+}
+
+// EaseInOut accelerates in the first half and decelerates in the second half.
+func EaseInOut(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	t = -2*t + 2
+	return 1 - t*t*t/2
+}
+
+// EaseInOutSine is a softer version of EaseInOut.
+func EaseInOutSine(t float64) float64 {
+	return -(math.Cos(math.Pi*t) - 1) / 2
+}
+
+// MoveMouseSmoothlyTo moves the mouse from its current position to screen
+// coordinates x,y along the path described by motion, taking the given
+// duration. Unlike MoveMouseTo, applications see all the intermediate mouse
+// positions.
+func MoveMouseSmoothlyTo(x, y int, duration time.Duration, motion MouseMotion) error {
+	fromX, fromY, err := MousePosition()
+	if err != nil {
+		return err
+	}
+
+	interval := motion.StepInterval
+	if interval <= 0 {
+		interval = 10 * time.Millisecond
+	}
+	steps := int(duration / interval)
+	if steps < 1 {
+		steps = 1
+	}
+
+	d := driver()
+	start := time.Now()
+	for i, p := range motion.Path(fromX, fromY, x, y, steps) {
+		if i > 0 {
+			// We schedule every step relative to the start so that the time it
+			// takes to inject the input does not add up.
+			time.Sleep(time.Until(start.Add(duration * time.Duration(i) / time.Duration(steps))))
+		}
+		if err := d.Inject(moveTo(p.X, p.Y)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Path returns the positions that the mouse moves through on its way from
+// fromX,fromY to toX,toY in the given number of steps. The start position is
+// not included, the last position is always toX,toY.
+//
+// MoveMouseSmoothlyTo moves the mouse to these positions, one per
+// StepInterval. Use Path to check the movement without moving the mouse.
+func (m MouseMotion) Path(fromX, fromY, toX, toY, steps int) []image.Point {
+	if steps < 1 {
+		steps = 1
+	}
+	easing := m.Easing
+	if easing == nil {
+		easing = EaseInOut
+	}
+	random := m.Rand
+	if random == nil && (m.Curvature != 0 || m.Jitter != 0) {
+		random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	start := vec{float64(fromX), float64(fromY)}
+	target := vec{float64(toX), float64(toY)}
+	dist := target.sub(start).length()
+
+	// With overshoot, the curve ends past the target and the last steps move
+	// back to it in a straight line.
+	end := target
+	curveSteps := steps
+	if m.Overshoot != 0 && dist > 0 && steps >= 2 {
+		end = target.add(target.sub(start).scale(m.Overshoot))
+		curveSteps = steps - steps/5
+		if curveSteps == steps {
+			curveSteps--
+		}
+	}
+
+	// The control points divide the line into thirds and are moved sideways,
+	// both to the same side, which gives a single smooth arc.
+	c1 := start.add(end.sub(start).scale(1.0 / 3))
+	c2 := start.add(end.sub(start).scale(2.0 / 3))
+	if l := end.sub(start).length(); m.Curvature != 0 && l > 0 {
+		normal := vec{start.y - end.y, end.x - start.x}.scale(1 / l)
+		side := 1.0
+		if random.Intn(2) == 0 {
+			side = -1
+		}
+		bend := dist * m.Curvature * side
+		c1 = c1.add(normal.scale(bend * (0.5 + random.Float64()/2)))
+		c2 = c2.add(normal.scale(bend * (0.5 + random.Float64()/2)))
+	}
+
+	path := make([]image.Point, 0, steps)
+	for i := 1; i <= curveSteps; i++ {
+		t := easing(float64(i) / float64(curveSteps))
+		path = append(path, m.jitter(bezier(start, c1, c2, end, t), random))
+	}
+	back := steps - curveSteps
+	for i := 1; i <= back; i++ {
+		t := easing(float64(i) / float64(back))
+		path = append(path, m.jitter(end.add(target.sub(end).scale(t)), random))
+	}
+	path[len(path)-1] = image.Pt(toX, toY)
+	return path
+}
+
+func (m MouseMotion) jitter(p vec, random *rand.Rand) image.Point {
+	if m.Jitter != 0 {
+		p.x += (random.Float64()*2 - 1) * m.Jitter
+		p.y += (random.Float64()*2 - 1) * m.Jitter
+	}
+	return image.Pt(int(math.Round(p.x)), int(math.Round(p.y)))
+}
+
+// bezier returns the point at t on the cubic Bezier curve with start point a,
+// control points b and c and end point d.
+func bezier(a, b, c, d vec, t float64) vec {
+	u := 1 - t
+	return a.scale(u * u * u).
+		add(b.scale(3 * u * u * t)).
+		add(c.scale(3 * u * t * t)).
+		add(d.scale(t * t * t))
+}
+
+type vec struct{ x, y float64 }
+
+func (v vec) add(w vec) vec {
+	return vec{v.x + w.x, v.y + w.y}
+}
+
+func (v vec) sub(w vec) vec {
+	return vec{v.x - w.x, v.y - w.y}
+}
+
+func (v vec) scale(f float64) vec {
+	return vec{v.x * f, v.y * f}
+}
+
+func (v vec) length() float64 {
+	return math.Hypot(v.x, v.y)
+}
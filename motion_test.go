@@ -0,0 +1,108 @@
+package auto
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestMouseMotionPathIsReproducible(t *testing.T) {
+	path := func(seed int64) []image.Point {
+		m := MouseMotion{
+			Curvature: 0.3,
+			Jitter:    2,
+			Overshoot: 0.1,
+			Rand:      rand.New(rand.NewSource(seed)),
+		}
+		return m.Path(10, 20, 500, 300, 50)
+	}
+	if a, b := path(1), path(1); !reflect.DeepEqual(a, b) {
+		t.Errorf("the same seed gives different paths:\n%v\n%v", a, b)
+	}
+}
+
+func TestMouseMotionPathEndsAtTarget(t *testing.T) {
+	motions := []MouseMotion{
+		{},
+		{Easing: EaseLinear},
+		{Curvature: 0.5},
+		{Jitter: 5},
+		{Overshoot: 0.2},
+		{Curvature: 0.2, Jitter: 1, Overshoot: 0.05, Easing: EaseInOutSine},
+	}
+	targets := []image.Point{{0, 0}, {1, 0}, {-300, 200}, {1000, 1000}}
+	for i, m := range motions {
+		m.Rand = rand.New(rand.NewSource(int64(i)))
+		for _, to := range targets {
+			for _, steps := range []int{-1, 0, 1, 2, 3, 5, 100} {
+				path := m.Path(0, 0, to.X, to.Y, steps)
+				wantLen := steps
+				if wantLen < 1 {
+					wantLen = 1
+				}
+				if len(path) != wantLen {
+					t.Errorf("motion %d to %v in %d steps: want %d points but have %d",
+						i, to, steps, wantLen, len(path))
+					continue
+				}
+				if last := path[len(path)-1]; last != to {
+					t.Errorf("motion %d to %v in %d steps: path ends at %v",
+						i, to, steps, last)
+				}
+			}
+		}
+	}
+}
+
+func TestMouseMotionStraightLine(t *testing.T) {
+	from, to := image.Pt(10, 20), image.Pt(410, 320)
+	length := math.Hypot(float64(to.X-from.X), float64(to.Y-from.Y))
+	ux, uy := float64(to.X-from.X)/length, float64(to.Y-from.Y)/length
+
+	// Without Curvature, Jitter and Overshoot there is no randomness.
+	path := MouseMotion{}.Path(from.X, from.Y, to.X, to.Y, 40)
+	progress := 0.0
+	for i, p := range path {
+		dx, dy := float64(p.X-from.X), float64(p.Y-from.Y)
+		// The distance from the line is the cross product with the unit
+		// direction, the progress along it is the dot product. Rounding to
+		// whole pixels can move a point by up to half a pixel in x and y.
+		if d := math.Abs(dx*uy - dy*ux); d > math.Sqrt2/2 {
+			t.Errorf("point %d %v is %.2f pixels off the line", i, p, d)
+		}
+		along := dx*ux + dy*uy
+		if along < progress-math.Sqrt2 || along > length+math.Sqrt2/2 {
+			t.Errorf("point %d %v goes back or past the target", i, p)
+		}
+		progress = along
+	}
+
+	// With linear easing the steps are evenly spaced.
+	path = MouseMotion{Easing: EaseLinear}.Path(from.X, from.Y, to.X, to.Y, 4)
+	want := []image.Point{{110, 95}, {210, 170}, {310, 245}, {410, 320}}
+	if !reflect.DeepEqual(path, want) {
+		t.Errorf("want linear path %v but have %v", want, path)
+	}
+}
+
+func TestEasings(t *testing.T) {
+	easings := map[string]Easing{
+		"EaseLinear":    EaseLinear,
+		"EaseIn":        EaseIn,
+		"EaseOut":       EaseOut,
+		"EaseInOut":     EaseInOut,
+		"EaseInOutSine": EaseInOutSine,
+	}
+	for name, e := range easings {
+		if e(0) != 0 || math.Abs(e(1)-1) > 1e-12 {
+			t.Errorf("%s(0) = %v and %s(1) = %v but want 0 and 1", name, e(0), name, e(1))
+		}
+		for i := 1; i <= 100; i++ {
+			if e(float64(i)/100) < e(float64(i-1)/100) {
+				t.Errorf("%s decreases at %v", name, float64(i)/100)
+			}
+		}
+	}
+}
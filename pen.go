@@ -0,0 +1,201 @@
+package auto
+
+import (
+	"errors"
+	"image"
+	"math"
+	"time"
+)
+
+// PenState is the state of a pen, or stylus, on a pen screen. The zero value
+// means the pen is out of range, i.e. away from the screen.
+type PenState struct {
+	// InRange means the pen is close enough to the screen to be detected. A
+	// pen that is in range but does not touch the screen hovers over it.
+	InRange bool
+	// Contact means the tip of the pen touches the screen. A pen in contact
+	// must also be in range.
+	Contact bool
+	// Pressure is how hard the tip is pressed onto the screen, from 0 to 1.
+	// It is ignored while the pen hovers.
+	Pressure float64
+	// TiltX and TiltY are the angles between the pen and the screen's normal
+	// in degrees, from -90 to 90. Positive TiltX tilts the top of the pen to
+	// the right, positive TiltY tilts it towards the bottom of the screen.
+	TiltX, TiltY int
+	// Eraser means the pen is turned around and uses its eraser end.
+	Eraser bool
+	// Barrel means the button on the side of the pen is held down.
+	Barrel bool
+}
+
+// MovePenTo moves the pen to screen coordinates x,y and puts it in the given
+// state. Use it to hover, touch the screen, draw, press the barrel button and
+// to take the pen away, e.g.
+//
+//	auto.MovePenTo(100, 100, auto.PenState{InRange: true})
+//	auto.MovePenTo(100, 100, auto.PenState{InRange: true, Contact: true, Pressure: 0.5})
+//	auto.MovePenTo(150, 120, auto.PenState{InRange: true, Contact: true, Pressure: 0.7})
+//	auto.MovePenTo(150, 120, auto.PenState{InRange: true})
+//	auto.MovePenTo(150, 120, auto.PenState{})
+//
+// The position is ignored when the pen goes out of range. See DrawPenStroke
+// for drawing whole lines.
+//
+// On Windows this creates a synthetic pen device, which needs Windows 10
+// version 1809 or newer. On Linux a virtual pen screen is created with uinput,
+// which usually requires root or membership in the input group. It covers the
+// whole screen.
+func MovePenTo(x, y int, state PenState) error {
+	return new(InputSequence).MovePenTo(x, y, state).Send()
+}
+
+// PressureProfile maps the progress along a pen stroke, from 0 to 1, to the
+// pen pressure, also from 0 to 1.
+type PressureProfile func(t float64) float64
+
+// ConstantPressure returns a PressureProfile with the same pressure along the
+// whole stroke.
+func ConstantPressure(pressure float64) PressureProfile {
+	return func(float64) float64 { return pressure }
+}
+
+// TaperedPressure returns a PressureProfile that rises from 0 to the given
+// pressure at the start of the stroke and falls back to 0 at its end, like a
+// brush stroke. taper is the part of the stroke, from 0 to 0.5, that it takes
+// to rise and to fall.
+func TaperedPressure(pressure, taper float64) PressureProfile {
+	return func(t float64) float64 {
+		if taper <= 0 {
+			return pressure
+		}
+		edge := math.Min(t, 1-t)
+		if edge >= taper {
+			return pressure
+		}
+		return pressure * edge / taper
+	}
+}
+
+// PenStroke is a line that the pen draws, see DrawPenStroke.
+type PenStroke struct {
+	// Points is the polyline that the pen follows, in screen coordinates. It
+	// must have at least one point.
+	Points []image.Point
+	// Pressure gives the pressure along the stroke. It defaults to
+	// ConstantPressure(0.5).
+	Pressure PressureProfile
+	// TiltX, TiltY, Eraser and Barrel are the pen state during the whole
+	// stroke, see PenState.
+	TiltX, TiltY int
+	Eraser       bool
+	Barrel       bool
+	// Duration is the time that the pen touches the screen. The pen moves
+	// along the polyline at constant speed. It defaults to 500ms.
+	Duration time.Duration
+	// StepInterval is the time between two pen moves. It defaults to 10ms.
+	StepInterval time.Duration
+}
+
+func (s PenStroke) stepInterval() time.Duration {
+	if s.StepInterval <= 0 {
+		return 10 * time.Millisecond
+	}
+	return s.StepInterval
+}
+
+// Events returns the pen input of the stroke, one event per step. The pen
+// hovers over the first point, touches the screen and moves along the
+// polyline, then it is lifted and goes out of range.
+func (s PenStroke) Events() []InputEvent {
+	if len(s.Points) == 0 {
+		return nil
+	}
+
+	pressure := s.Pressure
+	if pressure == nil {
+		pressure = ConstantPressure(0.5)
+	}
+	duration := s.Duration
+	if duration <= 0 {
+		duration = 500 * time.Millisecond
+	}
+	steps := int(duration / s.stepInterval())
+	if steps < 1 {
+		steps = 1
+	}
+
+	state := PenState{
+		InRange: true,
+		TiltX:   s.TiltX,
+		TiltY:   s.TiltY,
+		Eraser:  s.Eraser,
+		Barrel:  s.Barrel,
+	}
+	pen := func(p image.Point, state PenState) InputEvent {
+		return InputEvent{Type: InputPen, X: p.X, Y: p.Y, Pen: state}
+	}
+
+	first, last := s.Points[0], s.Points[len(s.Points)-1]
+	events := []InputEvent{pen(first, state)}
+	contact := state
+	contact.Contact = true
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		contact.Pressure = math.Max(0, math.Min(1, pressure(t)))
+		events = append(events, pen(pointOnPolyline(s.Points, t), contact))
+	}
+	return append(events, pen(last, state), pen(last, PenState{}))
+}
+
+// pointOnPolyline returns the point at the given part t, from 0 to 1, of the
+// length of the polyline.
+func pointOnPolyline(points []image.Point, t float64) image.Point {
+	var length float64
+	for i := 1; i < len(points); i++ {
+		length += distance(points[i-1], points[i])
+	}
+	left := t * length
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		d := distance(a, b)
+		if d > 0 && left <= d {
+			f := left / d
+			return image.Pt(
+				a.X+round(float64(b.X-a.X)*f),
+				a.Y+round(float64(b.Y-a.Y)*f),
+			)
+		}
+		left -= d
+	}
+	return points[len(points)-1]
+}
+
+func distance(a, b image.Point) float64 {
+	return math.Hypot(float64(b.X-a.X), float64(b.Y-a.Y))
+}
+
+// DrawPenStroke draws the given stroke with the pen, sending one pen move per
+// StepInterval, see PenStroke.Events. If sending fails, the pen is taken out
+// of range.
+func DrawPenStroke(stroke PenStroke) error {
+	if len(stroke.Points) == 0 {
+		return errors.New("a pen stroke needs at least one point")
+	}
+
+	s := new(InputSequence)
+	for i, e := range stroke.Events() {
+		if i > 0 {
+			s.Pause(stroke.stepInterval())
+		}
+		s.Add(e)
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	if err := s.Send(); err != nil {
+		driver().Inject(InputEvent{Type: InputPen})
+		return err
+	}
+	return nil
+}
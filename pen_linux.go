@@ -0,0 +1,160 @@
+package auto
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// These are the pen codes from linux/input-event-codes.h.
+const (
+	absPressure = 0x18
+	absTiltX    = 0x1A
+	absTiltY    = 0x1B
+
+	btnToolPen    = 0x140
+	btnToolRubber = 0x141
+	btnStylus     = 0x14B
+
+	// penPressureMax is the pressure of a pen that is pressed down fully.
+	penPressureMax = 4095
+)
+
+// penScreen is a virtual pen screen created with uinput. X11 has no way to
+// fake pen input, so we use it with and without an X server.
+var penScreen struct {
+	sync.Mutex
+	device        *os.File
+	width, height int
+	state         PenState
+}
+
+// injectPen sends the given pen events, one after the other.
+func injectPen(events []InputEvent) error {
+	penScreen.Lock()
+	defer penScreen.Unlock()
+
+	if penScreen.device == nil {
+		if err := createPenScreen(); err != nil {
+			return err
+		}
+	}
+	for _, e := range events {
+		if err := injectPenState(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createPenScreen creates the virtual pen screen. It spans the whole screen,
+// so we need to know the screen size. penScreen must be locked.
+func createPenScreen() error {
+	width, height, ok := screenSize()
+	if !ok {
+		return errors.New("cannot create a pen screen because the screen size is unknown")
+	}
+	var absMin, absMax [64]int32
+	absMax[absX] = int32(width - 1)
+	absMax[absY] = int32(height - 1)
+	absMax[absPressure] = penPressureMax
+	absMin[absTiltX], absMax[absTiltX] = -90, 90
+	absMin[absTiltY], absMax[absTiltY] = -90, 90
+	device, err := createUinputDevice("auto virtual pen screen", func(f *os.File) error {
+		// A direct input device is a pen screen, not a graphics tablet.
+		if err := ioctl(f, uiSetPropBit, inputPropDirect); err != nil {
+			return err
+		}
+		if err := ioctl(f, uiSetEvBit, evKey); err != nil {
+			return err
+		}
+		for _, code := range []uintptr{btnToolPen, btnToolRubber, btnTouch, btnStylus} {
+			if err := ioctl(f, uiSetKeyBit, code); err != nil {
+				return err
+			}
+		}
+		if err := ioctl(f, uiSetEvBit, evAbs); err != nil {
+			return err
+		}
+		for _, code := range []uintptr{absX, absY, absPressure, absTiltX, absTiltY} {
+			if err := ioctl(f, uiSetAbsBit, code); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, &absMin, &absMax)
+	if err != nil {
+		return err
+	}
+
+	// User space needs some time to pick up new devices. Events that are
+	// written before that are lost.
+	time.Sleep(200 * time.Millisecond)
+
+	penScreen.device = device
+	penScreen.width, penScreen.height = width, height
+	return nil
+}
+
+// injectPenState moves the pen from its current state to the state of the
+// given event. penScreen must be locked.
+func injectPenState(e InputEvent) error {
+	old, state := penScreen.state, e.Pen
+	var events []evdevEvent
+
+	// The tool leaves before the pen goes out of range or is turned around.
+	if old.InRange && (!state.InRange || old.Eraser != state.Eraser) {
+		events = append(events,
+			keyValue(btnTouch, false),
+			keyValue(btnStylus, false),
+			evdevEvent{typ: evAbs, code: absPressure, value: 0},
+			keyValue(penTool(old), false),
+			syn,
+		)
+		old = PenState{}
+	}
+
+	if state.InRange {
+		var pressure int32
+		if state.Contact {
+			pressure = int32(round(state.Pressure * penPressureMax))
+		}
+		events = append(events,
+			evdevEvent{typ: evAbs, code: absX, value: int32(clamp(e.X, 0, penScreen.width-1))},
+			evdevEvent{typ: evAbs, code: absY, value: int32(clamp(e.Y, 0, penScreen.height-1))},
+			evdevEvent{typ: evAbs, code: absPressure, value: pressure},
+			evdevEvent{typ: evAbs, code: absTiltX, value: int32(state.TiltX)},
+			evdevEvent{typ: evAbs, code: absTiltY, value: int32(state.TiltY)},
+		)
+		if !old.InRange {
+			events = append(events, keyValue(penTool(state), true))
+		}
+		if state.Contact != old.Contact {
+			events = append(events, keyValue(btnTouch, state.Contact))
+		}
+		if state.Barrel != old.Barrel {
+			events = append(events, keyValue(btnStylus, state.Barrel))
+		}
+		events = append(events, syn)
+	} else {
+		state = PenState{}
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+	if err := writeEvents(penScreen.device, events...); err != nil {
+		return err
+	}
+	penScreen.state = state
+	return nil
+}
+
+// penTool returns the tool button for the end of the pen that is in use.
+func penTool(state PenState) uint16 {
+	if state.Eraser {
+		return btnToolRubber
+	}
+	return btnToolPen
+}
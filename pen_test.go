@@ -0,0 +1,136 @@
+package auto
+
+import (
+	"image"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPenStrokeEvents(t *testing.T) {
+	stroke := PenStroke{
+		Points:       []image.Point{{0, 0}, {100, 0}},
+		Pressure:     ConstantPressure(0.25),
+		TiltX:        10,
+		TiltY:        -20,
+		Barrel:       true,
+		Duration:     40 * time.Millisecond,
+		StepInterval: 10 * time.Millisecond,
+	}
+	hover := PenState{InRange: true, TiltX: 10, TiltY: -20, Barrel: true}
+	contact := hover
+	contact.Contact = true
+	contact.Pressure = 0.25
+	pen := func(x int, state PenState) InputEvent {
+		return InputEvent{Type: InputPen, X: x, Pen: state}
+	}
+	want := []InputEvent{
+		pen(0, hover),
+		pen(0, contact),
+		pen(25, contact),
+		pen(50, contact),
+		pen(75, contact),
+		pen(100, contact),
+		pen(100, hover),
+		pen(100, PenState{}),
+	}
+	if got := stroke.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %v\nwant %v", got, want)
+	}
+
+	if events := (PenStroke{}).Events(); events != nil {
+		t.Errorf("a stroke without points has events %v", events)
+	}
+}
+
+func TestPenStrokePressureIsClamped(t *testing.T) {
+	for _, tt := range []struct{ pressure, want float64 }{
+		{-1, 0},
+		{0, 0},
+		{0.5, 0.5},
+		{1, 1},
+		{2, 1},
+		{math.Inf(1), 1},
+	} {
+		stroke := PenStroke{
+			Points:   []image.Point{{0, 0}, {10, 10}},
+			Pressure: ConstantPressure(tt.pressure),
+		}
+		for _, e := range stroke.Events() {
+			if e.Pen.Contact && e.Pen.Pressure != tt.want {
+				t.Errorf("pressure %v: want %v but have %v", tt.pressure, tt.want, e.Pen.Pressure)
+				break
+			}
+		}
+	}
+}
+
+func TestTaperedPressure(t *testing.T) {
+	p := TaperedPressure(0.8, 0.25)
+	for _, tt := range []struct{ t, want float64 }{
+		{0, 0},
+		{0.125, 0.4},
+		{0.25, 0.8},
+		{0.5, 0.8},
+		{0.75, 0.8},
+		{0.875, 0.4},
+		{1, 0},
+	} {
+		if got := p(tt.t); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("at %v: want %v but have %v", tt.t, tt.want, got)
+		}
+	}
+	if got := TaperedPressure(0.6, 0)(0); got != 0.6 {
+		t.Errorf("without taper want 0.6 at the start but have %v", got)
+	}
+}
+
+func TestPointOnPolyline(t *testing.T) {
+	// The zero length segments at the start, in the middle and at the end do
+	// not take up any part of the line.
+	points := []image.Point{{0, 0}, {0, 0}, {10, 0}, {10, 0}, {10, 10}, {10, 10}}
+	for _, tt := range []struct {
+		t    float64
+		want image.Point
+	}{
+		{0, image.Pt(0, 0)},
+		{0.25, image.Pt(5, 0)},
+		{0.5, image.Pt(10, 0)},
+		{0.75, image.Pt(10, 5)},
+		{1, image.Pt(10, 10)},
+	} {
+		if got := pointOnPolyline(points, tt.t); got != tt.want {
+			t.Errorf("at %v: want %v but have %v", tt.t, tt.want, got)
+		}
+	}
+
+	for _, points := range [][]image.Point{{{3, 4}}, {{3, 4}, {3, 4}}} {
+		for _, f := range []float64{0, 0.5, 1} {
+			if got := pointOnPolyline(points, f); got != image.Pt(3, 4) {
+				t.Errorf("%v at %v: want 3,4 but have %v", points, f, got)
+			}
+		}
+	}
+}
+
+func TestDrawPenStrokeTakesPenOutOfRangeOnError(t *testing.T) {
+	d := useFakeDriver(t)
+	d.failAt = 3
+	err := DrawPenStroke(PenStroke{
+		Points:       []image.Point{{0, 0}, {10, 0}},
+		Duration:     5 * time.Millisecond,
+		StepInterval: time.Millisecond,
+	})
+	if err == nil {
+		t.Fatal("want the error of the third pen move")
+	}
+	want := InputEvent{Type: InputPen}
+	if last := d.injected[len(d.injected)-1]; !reflect.DeepEqual(last, []InputEvent{want}) {
+		t.Errorf("the pen was not taken out of range, the last input is %v", last)
+	}
+
+	if err := DrawPenStroke(PenStroke{}); err == nil {
+		t.Error("a stroke without points was accepted")
+	}
+}
@@ -0,0 +1,163 @@
+package auto
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/gonutz/w32/v2"
+)
+
+var (
+	createSyntheticPointerDevice = user32.NewProc("CreateSyntheticPointerDevice")
+	injectSyntheticPointerInput  = user32.NewProc("InjectSyntheticPointerInput")
+)
+
+// pointerPenInfo is POINTER_PEN_INFO from the Windows API.
+type pointerPenInfo struct {
+	pointerInfo pointerInfo
+	penFlags    uint32
+	penMask     uint32
+	pressure    uint32
+	rotation    uint32
+	tiltX       int32
+	tiltY       int32
+}
+
+// pointerTypeInfo is POINTER_TYPE_INFO from the Windows API, with the pen
+// variant of its union. The union is as large as its touch variant and 8 byte
+// aligned.
+type pointerTypeInfo struct {
+	pointerType uint32
+	_           uint32
+	penInfo     pointerPenInfo
+	_           [unsafe.Sizeof(pointerTouchInfo{}) - unsafe.Sizeof(pointerPenInfo{})]byte
+}
+
+const (
+	ptPen = 3
+
+	penFlagBarrel   = 0x00000001
+	penFlagInverted = 0x00000002
+	penFlagEraser   = 0x00000004
+
+	penMaskPressure = 0x00000001
+	penMaskTiltX    = 0x00000004
+	penMaskTiltY    = 0x00000008
+
+	// penPressureMax is the pressure of a pen that is pressed down fully.
+	penPressureMax = 1024
+)
+
+// pen is the synthetic pen device and the state that we last gave it.
+var pen struct {
+	sync.Mutex
+	device uintptr
+	x, y   int
+	state  PenState
+}
+
+// injectPen sends the given pen events, one after the other.
+func injectPen(events []InputEvent) error {
+	pen.Lock()
+	defer pen.Unlock()
+
+	if pen.device == 0 {
+		if err := createSyntheticPointerDevice.Find(); err != nil {
+			return errors.New("pen input needs Windows 10 version 1809 or newer")
+		}
+		device, _, err := createSyntheticPointerDevice.Call(ptPen, 1, touchFeedbackDefault)
+		if device == 0 {
+			return fmt.Errorf("CreateSyntheticPointerDevice failed: %w", err)
+		}
+		pen.device = device
+	}
+
+	for _, e := range events {
+		if e.Pen.InRange {
+			pen.x, pen.y = e.X, e.Y
+		} else if pen.state.Contact {
+			// Windows wants the pen to be lifted before it leaves.
+			hover := pen.state
+			hover.Contact = false
+			if err := injectPenState(hover); err != nil {
+				return err
+			}
+		}
+		if err := injectPenState(e.Pen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// injectPenState moves the pen from its current state to the given state at
+// the current pen position. pen must be locked.
+func injectPenState(state PenState) error {
+	if !state.InRange && !pen.state.InRange {
+		return nil
+	}
+
+	var flags uint32
+	switch {
+	case state.Contact && !pen.state.Contact:
+		flags = pointerFlagDown
+	case !state.Contact && pen.state.Contact:
+		flags = pointerFlagUp
+	default:
+		flags = pointerFlagUpdate
+	}
+	if state.InRange {
+		flags |= pointerFlagInRange
+	}
+	if state.Contact {
+		flags |= pointerFlagInContact
+	}
+
+	var penFlags uint32
+	if state.Barrel {
+		penFlags |= penFlagBarrel
+	}
+	if state.Eraser {
+		penFlags |= penFlagInverted
+		if state.Contact {
+			penFlags |= penFlagEraser
+		}
+	}
+
+	var pressure uint32
+	if state.Contact {
+		pressure = uint32(round(state.Pressure * penPressureMax))
+	}
+
+	info := pointerTypeInfo{
+		pointerType: ptPen,
+		penInfo: pointerPenInfo{
+			pointerInfo: pointerInfo{
+				pointerType:     ptPen,
+				pointerFlags:    flags,
+				ptPixelLocation: w32.POINT{X: int32(pen.x), Y: int32(pen.y)},
+			},
+			penFlags: penFlags,
+			penMask:  penMaskPressure | penMaskTiltX | penMaskTiltY,
+			pressure: pressure,
+			tiltX:    int32(state.TiltX),
+			tiltY:    int32(state.TiltY),
+		},
+	}
+	ok, _, err := injectSyntheticPointerInput.Call(
+		pen.device,
+		uintptr(unsafe.Pointer(&info)),
+		1,
+	)
+	if ok == 0 {
+		if errno, isErrno := err.(syscall.Errno); isErrno && errno == 0 {
+			return errors.New("InjectSyntheticPointerInput failed")
+		}
+		return fmt.Errorf("InjectSyntheticPointerInput failed: %w", err)
+	}
+	pen.state = state
+	return nil
+}
@@ -0,0 +1,116 @@
+package auto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ScrollAt moves the mouse to screen coordinates x,y and rotates the mouse
+// wheel there, see MoveMouseWheelBy for the meaning of dx and dy. Most
+// applications scroll the view under the mouse, not the focussed one.
+func ScrollAt(x, y int, dx, dy float64) error {
+	return new(InputSequence).MoveMouseTo(x, y).MoveMouseWheelBy(dx, dy).Send()
+}
+
+// ScrollSmoothly rotates the mouse wheel by dx and dy ticks in small steps,
+// taking the given duration, see MoveMouseWheelBy for the meaning of dx and
+// dy. Applications that support high resolution scrolling, which most do on
+// Windows, scroll smoothly instead of jumping a whole tick at a time.
+//
+// On X11 the fractions still add up to whole ticks, so the scrolling happens
+// in jumps there.
+func ScrollSmoothly(dx, dy float64, duration time.Duration) error {
+	const stepInterval = 10 * time.Millisecond
+	steps := int(duration / stepInterval)
+	if steps < 1 {
+		steps = 1
+	}
+
+	// Windows has a resolution of 1/120 ticks. We round the total up to each
+	// step to that, instead of each step, so that rounding errors do not add
+	// up.
+	const resolution = 120
+	total := func(amount float64, step int) float64 {
+		return math.Round(amount*float64(step)/float64(steps)*resolution) / resolution
+	}
+
+	s := new(InputSequence)
+	for i := 1; i <= steps; i++ {
+		if i > 1 {
+			s.Pause(stepInterval)
+		}
+		stepX := total(dx, i) - total(dx, i-1)
+		stepY := total(dy, i) - total(dy, i-1)
+		if stepX != 0 || stepY != 0 {
+			s.MoveMouseWheelBy(stepX, stepY)
+		}
+	}
+	return s.Send()
+}
+
+// ScrollDirection is the direction that ScrollUntil scrolls the content in.
+type ScrollDirection int
+
+// These are the available ScrollDirections. ScrollUp rotates the wheel
+// forward, ScrollDown backward, ScrollLeft and ScrollRight use the horizontal
+// wheel.
+const (
+	ScrollUp ScrollDirection = iota + 1
+	ScrollDown
+	ScrollLeft
+	ScrollRight
+)
+
+// ErrScrollLimit is returned by ScrollUntil if the condition is still false
+// after scrolling the maximum number of ticks.
+var ErrScrollLimit = errors.New("auto: condition not met within the scroll limit")
+
+// scrollUntilDelay is the time that ScrollUntil gives the application to
+// update its view after each tick, before checking the condition.
+const scrollUntilDelay = 50 * time.Millisecond
+
+// ScrollUntil scrolls one wheel tick at a time in the given direction until
+// condition returns true, e.g. because an item is now visible in a screen
+// shot. The condition is checked before the first tick and after each tick.
+// The mouse is not moved, place it over the list first.
+//
+// ScrollUntil gives up after maxTicks ticks and returns ErrScrollLimit. It
+// returns the context's error if the context is done first.
+func ScrollUntil(ctx context.Context, direction ScrollDirection, condition func() bool, maxTicks int) error {
+	var dx, dy float64
+	switch direction {
+	case ScrollUp:
+		dy = 1
+	case ScrollDown:
+		dy = -1
+	case ScrollLeft:
+		dx = -1
+	case ScrollRight:
+		dx = 1
+	default:
+		return fmt.Errorf("invalid scroll direction %d", direction)
+	}
+
+	for tick := 0; ; tick++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if condition() {
+			return nil
+		}
+		if tick >= maxTicks {
+			return ErrScrollLimit
+		}
+		if err := MoveMouseWheelBy(dx, dy); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(scrollUntilDelay):
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package auto
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScrollSmoothlyAddsUpExactly(t *testing.T) {
+	tests := []struct {
+		dx, dy   float64
+		duration time.Duration
+	}{
+		{0, 1, 0},
+		{0, -3, 50 * time.Millisecond},
+		{0.35, -1.7, 300 * time.Millisecond},
+		{2.5, 0, 70 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		d := useFakeDriver(t)
+		if err := ScrollSmoothly(tt.dx, tt.dy, tt.duration); err != nil {
+			t.Fatal(err)
+		}
+		var sumX, sumY float64
+		for _, events := range d.injected {
+			for _, e := range events {
+				// Every step is a whole number of 1/120 ticks.
+				for _, amount := range []float64{e.WheelX, e.WheelY} {
+					if units := amount * 120; math.Abs(units-math.Round(units)) > 1e-9 {
+						t.Errorf("%v,%v: step %v is not a multiple of 1/120", tt.dx, tt.dy, amount)
+					}
+				}
+				sumX += e.WheelX
+				sumY += e.WheelY
+			}
+		}
+		if math.Abs(sumX-tt.dx) > 1e-9 || math.Abs(sumY-tt.dy) > 1e-9 {
+			t.Errorf("want %v,%v in total but have %v,%v", tt.dx, tt.dy, sumX, sumY)
+		}
+		steps := int(tt.duration / (10 * time.Millisecond))
+		if steps < 1 {
+			steps = 1
+		}
+		if len(d.injected) > steps {
+			t.Errorf("%v,%v: want at most %d steps but have %d", tt.dx, tt.dy, steps, len(d.injected))
+		}
+	}
+}
+
+func TestScrollUntilChecksBeforeFirstTick(t *testing.T) {
+	d := useFakeDriver(t)
+	err := ScrollUntil(context.Background(), ScrollDown, func() bool { return true }, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.injected) != 0 {
+		t.Errorf("scrolled although the condition was true: %v", d.injected)
+	}
+}
+
+func TestScrollUntilStopsWhenConditionIsMet(t *testing.T) {
+	d := useFakeDriver(t)
+	checks := 0
+	err := ScrollUntil(context.Background(), ScrollDown, func() bool {
+		checks++
+		return checks == 3
+	}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tick := []InputEvent{{Type: InputWheel, WheelY: -1}}
+	if want := [][]InputEvent{tick, tick}; !reflect.DeepEqual(d.injected, want) {
+		t.Errorf("\ngot  %v\nwant %v", d.injected, want)
+	}
+}
+
+func TestScrollUntilDirections(t *testing.T) {
+	directions := map[ScrollDirection]InputEvent{
+		ScrollUp:    {Type: InputWheel, WheelY: 1},
+		ScrollDown:  {Type: InputWheel, WheelY: -1},
+		ScrollLeft:  {Type: InputWheel, WheelX: -1},
+		ScrollRight: {Type: InputWheel, WheelX: 1},
+	}
+	for direction, tick := range directions {
+		d := useFakeDriver(t)
+		err := ScrollUntil(context.Background(), direction, func() bool { return false }, 2)
+		if !errors.Is(err, ErrScrollLimit) {
+			t.Errorf("direction %d: want ErrScrollLimit but got %v", direction, err)
+		}
+		want := [][]InputEvent{{tick}, {tick}}
+		if !reflect.DeepEqual(d.injected, want) {
+			t.Errorf("direction %d:\ngot  %v\nwant %v", direction, d.injected, want)
+		}
+	}
+}
+
+func TestScrollUntilRejectsInvalidDirections(t *testing.T) {
+	d := useFakeDriver(t)
+	for _, direction := range []ScrollDirection{0, ScrollRight + 1, -1} {
+		err := ScrollUntil(context.Background(), direction, func() bool { return false }, 1)
+		if err == nil {
+			t.Errorf("direction %d was accepted", direction)
+		}
+	}
+	if len(d.injected) != 0 {
+		t.Errorf("scrolled in an invalid direction: %v", d.injected)
+	}
+}
+
+func TestScrollUntilStopsWhenContextIsDone(t *testing.T) {
+	d := useFakeDriver(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := ScrollUntil(ctx, ScrollUp, func() bool {
+		t.Error("the condition was checked with a cancelled context")
+		return false
+	}, 5)
+	if err != context.Canceled {
+		t.Errorf("want context.Canceled but got %v", err)
+	}
+
+	// Cancelling while waiting for the application stops as well.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	err = ScrollUntil(ctx, ScrollUp, func() bool {
+		cancel()
+		return false
+	}, 5)
+	if err != context.Canceled {
+		t.Errorf("want context.Canceled but got %v", err)
+	}
+	if len(d.injected) != 1 {
+		t.Errorf("want one tick before the cancellation but have %v", d.injected)
+	}
+}
@@ -0,0 +1,307 @@
+package auto
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SendKeys types text and special keys described by a small language, similar
+// to AutoHotkey's Send command:
+//
+//	Hello             types the text Hello
+//	{Enter}           presses and releases a key, see ParseKey for the names
+//	{Tab 3}           presses and releases a key 3 times
+//	{Ctrl down}       presses a key and keeps it down
+//	{Ctrl up}         releases a key
+//	{Sleep 200}       waits 200 milliseconds
+//	^c                Ctrl+C, the modifiers are ^ Ctrl, + Shift, ! Alt, # Win
+//	^+{Left 2}        Ctrl+Shift+Left, twice
+//	{{} {}} {^} {+}   type the characters { } ^ + and the same for ! and #
+//
+// Modifiers apply to the next letter, digit, space or {key}. Letters are
+// typed as their keys, so ^c and ^C are both Ctrl+C. To press the Sleep key,
+// write {KeySleep}.
+//
+// The whole string is checked before anything is sent. Syntax errors are
+// returned as *SyntaxError with the column of the problem. If sending fails,
+// the keys that were pressed with {key down} are released.
+func SendKeys(keys string) error {
+	actions, err := parseSendKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	var held []uint16
+	release := func(key uint16) {
+		for i := len(held) - 1; i >= 0; i-- {
+			if held[i] == key {
+				held = append(held[:i], held[i+1:]...)
+				return
+			}
+		}
+	}
+
+	for _, a := range actions {
+		var err error
+		switch a.kind {
+		case sendText:
+			err = Type(a.text)
+		case sendChord:
+			for i := 0; i < a.repeat && err == nil; i++ {
+				err = typeChord(a.keys)
+			}
+		case sendKeyDown:
+			err = PressKey(a.keys[0])
+			held = append(held, a.keys[0])
+		case sendKeyUp:
+			err = ReleaseKey(a.keys[0])
+			release(a.keys[0])
+		case sendSleep:
+			time.Sleep(a.sleep)
+		}
+		if err != nil {
+			for i := len(held) - 1; i >= 0; i-- {
+				ReleaseKey(held[i])
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// SyntaxError is returned by SendKeys for invalid input. Column is the
+// position of the problem, counting runes from 1.
+type SyntaxError struct {
+	Column  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error in column %d: %s", e.Column, e.Message)
+}
+
+type sendKind int
+
+const (
+	sendText sendKind = iota
+	sendChord
+	sendKeyDown
+	sendKeyUp
+	sendSleep
+)
+
+// sendAction is a single step of SendKeys.
+type sendAction struct {
+	kind sendKind
+	// text is typed for sendText.
+	text string
+	// keys are pressed in order and released in reverse order for sendChord.
+	// sendKeyDown and sendKeyUp have a single key.
+	keys   []uint16
+	repeat int
+	sleep  time.Duration
+}
+
+// sendModifiers are the keys of the modifier prefixes.
+var sendModifiers = map[rune]uint16{
+	'^': KeyControl,
+	'+': KeyShift,
+	'!': KeyAlt,
+	'#': KeyLeftWin,
+}
+
+// parseSendKeys turns a SendKeys string into the actions that it describes.
+func parseSendKeys(spec string) ([]sendAction, error) {
+	runes := []rune(spec)
+	var actions []sendAction
+	var text []rune
+	var modifiers []uint16
+	modifierColumn := 0
+
+	flushText := func() {
+		if len(text) > 0 {
+			actions = append(actions, sendAction{kind: sendText, text: string(text)})
+			text = nil
+		}
+	}
+	syntaxError := func(i int, format string, a ...interface{}) error {
+		return &SyntaxError{Column: i + 1, Message: fmt.Sprintf(format, a...)}
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if key, ok := sendModifiers[r]; ok {
+			for _, m := range modifiers {
+				if m == key {
+					return nil, syntaxError(i, "modifier %c is given twice", r)
+				}
+			}
+			if len(modifiers) == 0 {
+				modifierColumn = i
+			}
+			modifiers = append(modifiers, key)
+			continue
+		}
+
+		if r == '}' {
+			return nil, syntaxError(i, "unexpected }, write {}} to type it")
+		}
+
+		if r != '{' {
+			if len(modifiers) == 0 {
+				text = append(text, r)
+				continue
+			}
+			key, ok := sendCharKey(r)
+			if !ok {
+				return nil, syntaxError(i, "modifiers only work with letters, digits, space or {key}, not %q", r)
+			}
+			flushText()
+			actions = append(actions, sendAction{
+				kind:   sendChord,
+				keys:   append(modifiers, key),
+				repeat: 1,
+			})
+			modifiers = nil
+			continue
+		}
+
+		// We are at a {. Find the matching }, the first } right after the {
+		// is part of the content, which is how {}} types a }.
+		open := i
+		if i+1 < len(runes) && runes[i+1] == '}' && (i+2 >= len(runes) || runes[i+2] != '}') {
+			return nil, syntaxError(open, "empty {}")
+		}
+		end := i + 2
+		for end < len(runes) && runes[end] != '}' {
+			end++
+		}
+		if i+1 >= len(runes) || end >= len(runes) {
+			return nil, syntaxError(open, "{ is not closed, write {{} to type it")
+		}
+		content := runes[open+1 : end]
+		i = end
+		fields := splitFields(content, open+1)
+		if len(fields) == 0 {
+			return nil, syntaxError(open, "empty {}")
+		}
+		if len(fields) > 2 {
+			return nil, syntaxError(fields[2].column, "unexpected %q, a {key} can have one argument", fields[2].text)
+		}
+		name := fields[0]
+
+		// Escaped characters.
+		if len(fields) == 1 && len([]rune(name.text)) == 1 && strings.ContainsRune("{}^+!#", []rune(name.text)[0]) {
+			if len(modifiers) > 0 {
+				return nil, syntaxError(name.column, "modifiers only work with letters, digits, space or {key}, not %q", name.text)
+			}
+			text = append(text, []rune(name.text)...)
+			continue
+		}
+
+		flushText()
+
+		if strings.EqualFold(name.text, "Sleep") {
+			if len(modifiers) > 0 {
+				return nil, syntaxError(modifierColumn, "modifiers cannot be applied to {Sleep}")
+			}
+			if len(fields) != 2 {
+				return nil, syntaxError(name.column, "{Sleep} needs the number of milliseconds, e.g. {Sleep 100}")
+			}
+			ms, err := strconv.Atoi(fields[1].text)
+			if err != nil || ms < 0 {
+				return nil, syntaxError(fields[1].column, "invalid number of milliseconds %q", fields[1].text)
+			}
+			if int64(ms) > math.MaxInt64/int64(time.Millisecond) {
+				return nil, syntaxError(fields[1].column, "{Sleep %s} is too long, it can be at most %d milliseconds",
+					fields[1].text, math.MaxInt64/int64(time.Millisecond))
+			}
+			actions = append(actions, sendAction{
+				kind:  sendSleep,
+				sleep: time.Duration(ms) * time.Millisecond,
+			})
+			continue
+		}
+
+		key, err := ParseKey(name.text)
+		if err != nil {
+			return nil, syntaxError(name.column, "unknown key %q", name.text)
+		}
+
+		action := sendAction{kind: sendChord, keys: append(modifiers, key), repeat: 1}
+		if len(fields) == 2 {
+			arg := fields[1]
+			switch strings.ToLower(arg.text) {
+			case "down", "up":
+				if len(modifiers) > 0 {
+					return nil, syntaxError(modifierColumn, "modifiers cannot be combined with %s", arg.text)
+				}
+				action.kind = sendKeyDown
+				if strings.EqualFold(arg.text, "up") {
+					action.kind = sendKeyUp
+				}
+			default:
+				n, err := strconv.Atoi(arg.text)
+				if err != nil || n < 0 {
+					return nil, syntaxError(arg.column, "expected down, up or a repeat count, not %q", arg.text)
+				}
+				action.repeat = n
+			}
+		}
+		actions = append(actions, action)
+		modifiers = nil
+	}
+
+	if len(modifiers) > 0 {
+		return nil, syntaxError(modifierColumn, "modifiers at the end, they must be followed by a key")
+	}
+	flushText()
+	return actions, nil
+}
+
+// sendCharKey returns the key for a character that follows modifiers.
+func sendCharKey(r rune) (uint16, bool) {
+	switch {
+	case 'a' <= r && r <= 'z':
+		return uint16(unicode.ToUpper(r)), true
+	case 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		return uint16(r), true
+	case r == ' ':
+		return KeySpace, true
+	}
+	return 0, false
+}
+
+// sendField is a word inside braces and its 0 based column.
+type sendField struct {
+	text   string
+	column int
+}
+
+// splitFields splits the content of braces at white space. offset is the
+// column of the content's first rune.
+func splitFields(content []rune, offset int) []sendField {
+	var fields []sendField
+	start := -1
+	for i := 0; i <= len(content); i++ {
+		if i < len(content) && !unicode.IsSpace(content[i]) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			fields = append(fields, sendField{
+				text:   string(content[start:i]),
+				column: offset + start,
+			})
+			start = -1
+		}
+	}
+	return fields
+}
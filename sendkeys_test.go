@@ -0,0 +1,133 @@
+package auto
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestParseSendKeys(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []sendAction
+	}{
+		{"", nil},
+		{"Hello", []sendAction{{kind: sendText, text: "Hello"}}},
+		{"{Enter}", []sendAction{{kind: sendChord, keys: []uint16{KeyEnter}, repeat: 1}}},
+		{"{enter}", []sendAction{{kind: sendChord, keys: []uint16{KeyEnter}, repeat: 1}}},
+		{"{Tab 3}", []sendAction{{kind: sendChord, keys: []uint16{KeyTab}, repeat: 3}}},
+		{"{Tab 0}", []sendAction{{kind: sendChord, keys: []uint16{KeyTab}, repeat: 0}}},
+		{"{ Tab  3 }", []sendAction{{kind: sendChord, keys: []uint16{KeyTab}, repeat: 3}}},
+		{"{Ctrl down}", []sendAction{{kind: sendKeyDown, keys: []uint16{KeyControl}, repeat: 1}}},
+		{"{Ctrl UP}", []sendAction{{kind: sendKeyUp, keys: []uint16{KeyControl}, repeat: 1}}},
+		{"{Sleep 200}", []sendAction{{kind: sendSleep, sleep: 200 * time.Millisecond}}},
+		{"{Sleep 9223372036854}", []sendAction{{kind: sendSleep, sleep: 9223372036854 * time.Millisecond}}},
+		{"{KeySleep}", []sendAction{{kind: sendChord, keys: []uint16{KeySleep}, repeat: 1}}},
+		{"^c", []sendAction{{kind: sendChord, keys: []uint16{KeyControl, KeyC}, repeat: 1}}},
+		{"^C", []sendAction{{kind: sendChord, keys: []uint16{KeyControl, KeyC}, repeat: 1}}},
+		{"+1", []sendAction{{kind: sendChord, keys: []uint16{KeyShift, Key1}, repeat: 1}}},
+		{"! ", []sendAction{{kind: sendChord, keys: []uint16{KeyAlt, KeySpace}, repeat: 1}}},
+		{"#e", []sendAction{{kind: sendChord, keys: []uint16{KeyLeftWin, KeyE}, repeat: 1}}},
+		{"^+{Left 2}", []sendAction{{kind: sendChord, keys: []uint16{KeyControl, KeyShift, KeyLeft}, repeat: 2}}},
+		{"{{}{}}{^}{+}{!}{#}", []sendAction{{kind: sendText, text: "{}^+!#"}}},
+		{"a{Enter}b", []sendAction{
+			{kind: sendText, text: "a"},
+			{kind: sendChord, keys: []uint16{KeyEnter}, repeat: 1},
+			{kind: sendText, text: "b"},
+		}},
+		{"x^ay", []sendAction{
+			{kind: sendText, text: "x"},
+			{kind: sendChord, keys: []uint16{KeyControl, KeyA}, repeat: 1},
+			{kind: sendText, text: "y"},
+		}},
+		{"{Shift down}ab{Shift up}", []sendAction{
+			{kind: sendKeyDown, keys: []uint16{KeyShift}, repeat: 1},
+			{kind: sendText, text: "ab"},
+			{kind: sendKeyUp, keys: []uint16{KeyShift}, repeat: 1},
+		}},
+		{"Grüße 😀", []sendAction{{kind: sendText, text: "Grüße 😀"}}},
+	}
+	for _, tt := range tests {
+		got, err := parseSendKeys(tt.spec)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q:\ngot  %+v\nwant %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseSendKeysErrorColumns(t *testing.T) {
+	tests := []struct {
+		spec   string
+		column int
+	}{
+		{"}", 1},
+		{"ab}", 3},
+		{"{", 1},
+		{"ab{Enter", 3},
+		{"{}", 1},
+		{"x{ }", 2},
+		{"{Nope}", 2},
+		{"ab{ Nope}", 5},
+		{"{Tab x}", 6},
+		{"{Tab 1 2}", 8},
+		{"{Sleep}", 2},
+		{"{Sleep -1}", 8},
+		{"{Sleep 9223372036855}", 8},
+		{"x{Sleep 99999999999999999999}", 9},
+		{"^{Sleep 1}", 1},
+		{"a^{Ctrl down}", 2},
+		{"^^a", 2},
+		{"^.", 2},
+		{"^{^}", 3},
+		{"ö^", 2},
+		{"😀^+", 2},
+	}
+	for _, tt := range tests {
+		_, err := parseSendKeys(tt.spec)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%q: want a *SyntaxError but got %v", tt.spec, err)
+			continue
+		}
+		if syntaxErr.Column != tt.column {
+			t.Errorf("%q: want column %d but got %d (%v)", tt.spec, tt.column, syntaxErr.Column, err)
+		}
+	}
+}
+
+func FuzzSendKeysParse(f *testing.F) {
+	for _, seed := range []string{
+		"Hello",
+		"{Enter}",
+		"^+{Left 2}",
+		"{{}{}}",
+		"{Ctrl down}c{Ctrl up}",
+		"{Sleep 10}",
+		"{",
+		"}",
+		"^",
+		"{Tab x}",
+		"😀{Tab}",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, spec string) {
+		_, err := parseSendKeys(spec)
+		if err == nil {
+			return
+		}
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("%q: want a *SyntaxError but got %v", spec, err)
+		}
+		if n := utf8.RuneCountInString(spec); syntaxErr.Column < 1 || syntaxErr.Column > n+1 {
+			t.Fatalf("%q: column %d is outside 1..%d", spec, syntaxErr.Column, n+1)
+		}
+	})
+}
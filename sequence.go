@@ -0,0 +1,209 @@
+package auto
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// InputSequence is a list of mouse and keyboard input that is sent as a whole.
+// Build it by chaining its methods and call Send at the end, e.g.
+//
+//	err := new(auto.InputSequence).
+//		MoveMouseTo(100, 200).
+//		ClickMouse(auto.LeftMouseButton).
+//		Pause(100 * time.Millisecond).
+//		TypeKey(auto.KeyEnter).
+//		Send()
+//
+// All input is checked before anything is sent. If any of it is invalid, Send
+// returns the first error and sends nothing. The input between two pauses is
+// given to the Driver in a single Inject call, which means that other input,
+// e.g. from the user or another goroutine, cannot come in between. On Windows
+// all input of a batch goes out in one SendInput call.
+//
+// The zero value is an empty sequence, ready to use.
+type InputSequence struct {
+	batches []inputBatch
+	count   int
+	err     error
+}
+
+// inputBatch is input that is sent in one Inject call, followed by a pause.
+type inputBatch struct {
+	events []InputEvent
+	pause  time.Duration
+}
+
+// Add appends the given events to the sequence.
+func (s *InputSequence) Add(events ...InputEvent) *InputSequence {
+	for _, e := range events {
+		if s.err != nil {
+			return s
+		}
+		if err := validateInputEvent(e); err != nil {
+			s.err = fmt.Errorf("input %d: %w", s.count, err)
+			return s
+		}
+		if len(s.batches) == 0 || s.batches[len(s.batches)-1].pause > 0 {
+			s.batches = append(s.batches, inputBatch{})
+		}
+		last := &s.batches[len(s.batches)-1]
+		last.events = append(last.events, e)
+		s.count++
+	}
+	return s
+}
+
+func validateInputEvent(e InputEvent) error {
+	switch e.Type {
+	case InputKeyDown, InputKeyUp:
+		if e.Key == 0 || e.Key > 0xFE {
+			return fmt.Errorf("invalid key code %d", e.Key)
+		}
+	case InputButtonDown, InputButtonUp:
+		if e.Button < LeftMouseButton || e.Button > X2MouseButton {
+			return fmt.Errorf("invalid mouse button %d", e.Button)
+		}
+	case InputMoveTo, InputMoveBy, InputMoveRaw:
+	case InputTouchDown, InputTouchMove, InputTouchUp:
+		if e.Contact < 0 || e.Contact >= MaxTouchContacts {
+			return fmt.Errorf("invalid touch contact %d", e.Contact)
+		}
+	case InputPen:
+		p := e.Pen
+		if p.Contact && !p.InRange {
+			return errors.New("a pen that touches the screen must be in range")
+		}
+		if !(p.Pressure >= 0 && p.Pressure <= 1) {
+			return fmt.Errorf("invalid pen pressure %v, it must be from 0 to 1", p.Pressure)
+		}
+		if p.TiltX < -90 || p.TiltX > 90 || p.TiltY < -90 || p.TiltY > 90 {
+			return fmt.Errorf("invalid pen tilt %d,%d, it must be from -90 to 90 degrees", p.TiltX, p.TiltY)
+		}
+	case InputWheel:
+		if math.IsNaN(e.WheelX) || math.IsInf(e.WheelX, 0) ||
+			math.IsNaN(e.WheelY) || math.IsInf(e.WheelY, 0) {
+			return errors.New("the mouse wheel rotation must be a finite number")
+		}
+	default:
+		return fmt.Errorf("invalid input event type %d", e.Type)
+	}
+	return nil
+}
+
+// MoveMouseTo moves the mouse to the given screen coordinates.
+func (s *InputSequence) MoveMouseTo(x, y int) *InputSequence {
+	return s.Add(moveTo(x, y))
+}
+
+// MoveMouseBy moves the mouse by the given number of pixels.
+func (s *InputSequence) MoveMouseBy(dx, dy int) *InputSequence {
+	return s.Add(InputEvent{Type: InputMoveBy, X: dx, Y: dy})
+}
+
+// MoveMouseByRaw sends relative mouse motion, see the package level
+// MoveMouseByRaw.
+func (s *InputSequence) MoveMouseByRaw(dx, dy int) *InputSequence {
+	return s.Add(InputEvent{Type: InputMoveRaw, X: dx, Y: dy})
+}
+
+// PressMouse presses the given mouse button down.
+func (s *InputSequence) PressMouse(b MouseButton) *InputSequence {
+	return s.Add(buttonDown(b))
+}
+
+// ReleaseMouse releases the given mouse button.
+func (s *InputSequence) ReleaseMouse(b MouseButton) *InputSequence {
+	return s.Add(buttonUp(b))
+}
+
+// ClickMouse presses and releases the given mouse button.
+func (s *InputSequence) ClickMouse(b MouseButton) *InputSequence {
+	return s.Add(buttonDown(b), buttonUp(b))
+}
+
+// MoveMouseWheelBy rotates the mouse wheel, see the package level
+// MoveMouseWheelBy for the meaning of dx and dy.
+func (s *InputSequence) MoveMouseWheelBy(dx, dy float64) *InputSequence {
+	return s.Add(InputEvent{Type: InputWheel, WheelX: dx, WheelY: dy})
+}
+
+// PressKey presses the given key down, see the Key... constants.
+func (s *InputSequence) PressKey(key uint16) *InputSequence {
+	return s.Add(keyDown(key))
+}
+
+// ReleaseKey releases the given key, see the Key... constants.
+func (s *InputSequence) ReleaseKey(key uint16) *InputSequence {
+	return s.Add(keyUp(key))
+}
+
+// TypeKey presses and releases the given key, see the Key... constants.
+func (s *InputSequence) TypeKey(key uint16) *InputSequence {
+	return s.Add(keyDown(key), keyUp(key))
+}
+
+// TouchDown puts a finger on the touch screen, see the package level
+// TouchDown.
+func (s *InputSequence) TouchDown(contact, x, y int) *InputSequence {
+	return s.Add(InputEvent{Type: InputTouchDown, Contact: contact, X: x, Y: y})
+}
+
+// TouchMove moves a finger on the touch screen.
+func (s *InputSequence) TouchMove(contact, x, y int) *InputSequence {
+	return s.Add(InputEvent{Type: InputTouchMove, Contact: contact, X: x, Y: y})
+}
+
+// TouchUp lifts a finger off the touch screen.
+func (s *InputSequence) TouchUp(contact int) *InputSequence {
+	return s.Add(InputEvent{Type: InputTouchUp, Contact: contact})
+}
+
+// MovePenTo moves the pen and changes its state, see the package level
+// MovePenTo.
+func (s *InputSequence) MovePenTo(x, y int, state PenState) *InputSequence {
+	return s.Add(InputEvent{Type: InputPen, X: x, Y: y, Pen: state})
+}
+
+// Pause waits for the given time before sending the rest of the sequence.
+// Input before and after the pause is sent in separate batches.
+func (s *InputSequence) Pause(d time.Duration) *InputSequence {
+	if s.err != nil {
+		return s
+	}
+	if d < 0 {
+		s.err = fmt.Errorf("pause after input %d: negative duration %v", s.count, d)
+		return s
+	}
+	if len(s.batches) == 0 {
+		s.batches = append(s.batches, inputBatch{})
+	}
+	s.batches[len(s.batches)-1].pause += d
+	return s
+}
+
+// Err returns the first error in the sequence, if any. Send returns this error
+// without sending anything.
+func (s *InputSequence) Err() error {
+	return s.err
+}
+
+// Send sends the input of the sequence to the current Driver, in batches
+// between the pauses. It stops at the first error.
+func (s *InputSequence) Send() error {
+	if s.err != nil {
+		return s.err
+	}
+	d := driver()
+	for _, b := range s.batches {
+		if len(b.events) > 0 {
+			if err := d.Inject(b.events...); err != nil {
+				return err
+			}
+		}
+		time.Sleep(b.pause)
+	}
+	return nil
+}
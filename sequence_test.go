@@ -0,0 +1,133 @@
+package auto
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestInputSequenceRejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name string
+		add  func(s *InputSequence)
+	}{
+		{"key 0", func(s *InputSequence) { s.PressKey(0) }},
+		{"key 0xFF", func(s *InputSequence) { s.ReleaseKey(0xFF) }},
+		{"button 0", func(s *InputSequence) { s.PressMouse(0) }},
+		{"button 6", func(s *InputSequence) { s.ClickMouse(X2MouseButton + 1) }},
+		{"contact -1", func(s *InputSequence) { s.TouchDown(-1, 0, 0) }},
+		{"contact too big", func(s *InputSequence) { s.TouchUp(MaxTouchContacts) }},
+		{"contact out of range", func(s *InputSequence) {
+			s.MovePenTo(0, 0, PenState{Contact: true, Pressure: 0.5})
+		}},
+		{"pressure -0.1", func(s *InputSequence) {
+			s.MovePenTo(0, 0, PenState{InRange: true, Contact: true, Pressure: -0.1})
+		}},
+		{"pressure 1.1", func(s *InputSequence) {
+			s.MovePenTo(0, 0, PenState{InRange: true, Contact: true, Pressure: 1.1})
+		}},
+		{"pressure NaN", func(s *InputSequence) {
+			s.MovePenTo(0, 0, PenState{InRange: true, Pressure: math.NaN()})
+		}},
+		{"tilt x 91", func(s *InputSequence) { s.MovePenTo(0, 0, PenState{InRange: true, TiltX: 91}) }},
+		{"tilt y -91", func(s *InputSequence) { s.MovePenTo(0, 0, PenState{InRange: true, TiltY: -91}) }},
+		{"wheel x NaN", func(s *InputSequence) { s.MoveMouseWheelBy(math.NaN(), 0) }},
+		{"wheel y infinite", func(s *InputSequence) { s.MoveMouseWheelBy(0, math.Inf(-1)) }},
+		{"event type 0", func(s *InputSequence) { s.Add(InputEvent{}) }},
+		{"negative pause", func(s *InputSequence) { s.Pause(-time.Millisecond) }},
+	}
+	for _, tt := range tests {
+		d := useFakeDriver(t)
+		s := new(InputSequence).TypeKey(KeyA).Pause(time.Millisecond)
+		tt.add(s)
+		// Valid input after the error does not reset it.
+		s.TypeKey(KeyB)
+		if s.Err() == nil {
+			t.Errorf("%s: Err is nil", tt.name)
+		}
+		if err := s.Send(); err == nil || err != s.Err() {
+			t.Errorf("%s: Send returned %v, not Err %v", tt.name, err, s.Err())
+		}
+		if len(d.injected) != 0 || d.calls != 0 {
+			t.Errorf("%s: input was sent: %v", tt.name, d.injected)
+		}
+	}
+}
+
+func TestInputSequenceAcceptsValidInput(t *testing.T) {
+	s := new(InputSequence).
+		TypeKey(KeyA).
+		ClickMouse(X2MouseButton).
+		MoveMouseTo(-10, 20).
+		MoveMouseBy(1, 2).
+		MoveMouseByRaw(3, 4).
+		MoveMouseWheelBy(0.5, -1).
+		TouchDown(0, 1, 1).
+		TouchMove(MaxTouchContacts-1, 2, 2).
+		TouchUp(0).
+		MovePenTo(0, 0, PenState{InRange: true, Contact: true, Pressure: 1, TiltX: -90, TiltY: 90}).
+		Pause(0)
+	if err := s.Err(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInputSequenceSendsBatchesBetweenPauses(t *testing.T) {
+	d := useFakeDriver(t)
+	err := new(InputSequence).
+		Pause(time.Millisecond).
+		TypeKey(KeyA).
+		MoveMouseTo(1, 2).
+		Pause(time.Millisecond).
+		Pause(time.Millisecond).
+		ClickMouse(LeftMouseButton).
+		Pause(time.Millisecond).
+		PressKey(KeyB).
+		Send()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]InputEvent{
+		{keyDown(KeyA), keyUp(KeyA), moveTo(1, 2)},
+		{buttonDown(LeftMouseButton), buttonUp(LeftMouseButton)},
+		{keyDown(KeyB)},
+	}
+	if !reflect.DeepEqual(d.injected, want) {
+		t.Errorf("\ngot  %v\nwant %v", d.injected, want)
+	}
+}
+
+func TestInputSequencePauses(t *testing.T) {
+	useFakeDriver(t)
+	const pause = 20 * time.Millisecond
+	start := time.Now()
+	err := new(InputSequence).TypeKey(KeyA).Pause(pause).Pause(pause).TypeKey(KeyB).Send()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 2*pause {
+		t.Errorf("two pauses of %v took only %v", pause, d)
+	}
+}
+
+func TestInputSequenceStopsAtFailingBatch(t *testing.T) {
+	d := useFakeDriver(t)
+	d.failAt = 2
+	err := new(InputSequence).
+		TypeKey(KeyA).
+		Pause(time.Millisecond).
+		TypeKey(KeyB).
+		Pause(time.Millisecond).
+		TypeKey(KeyC).
+		Send()
+	if err == nil {
+		t.Error("want the error of the second batch")
+	}
+	if d.calls != 2 {
+		t.Errorf("want 2 Inject calls but have %d", d.calls)
+	}
+	if want := [][]InputEvent{{keyDown(KeyA), keyUp(KeyA)}}; !reflect.DeepEqual(d.injected, want) {
+		t.Errorf("\ngot  %v\nwant %v", d.injected, want)
+	}
+}
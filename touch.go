@@ -0,0 +1,287 @@
+package auto
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// MaxTouchContacts is the number of fingers that can touch the screen at the
+// same time. Touch contacts are numbered from 0 to MaxTouchContacts-1.
+const MaxTouchContacts = 10
+
+// TouchDown puts the finger with the given contact number on the touch screen
+// at screen coordinates x,y. Move it with TouchMove and lift it with TouchUp.
+//
+// To move multiple fingers at the same time, e.g. for a pinch, use an
+// InputSequence. Consecutive touch input for different fingers is sent as one
+// frame, i.e. it happens at the same time.
+//
+// On Windows touch input is generated with InjectTouchInput. Windows cancels
+// touch contacts that are not updated for a while, so keep calling TouchMove,
+// even with the same position, while a finger stays on the screen. On Linux a
+// virtual touch screen is created with uinput, which usually requires root or
+// membership in the input group. It covers the whole screen.
+func TouchDown(contact, x, y int) error {
+	return new(InputSequence).TouchDown(contact, x, y).Send()
+}
+
+// TouchMove moves the finger with the given contact number, which is on the
+// touch screen, to screen coordinates x,y.
+func TouchMove(contact, x, y int) error {
+	return new(InputSequence).TouchMove(contact, x, y).Send()
+}
+
+// TouchUp lifts the finger with the given contact number off the touch screen.
+func TouchUp(contact int) error {
+	return new(InputSequence).TouchUp(contact).Send()
+}
+
+// TouchPoint is the position of a finger on the touch screen.
+type TouchPoint struct {
+	Contact int
+	X, Y    int
+}
+
+// Gesture is a touch gesture, a list of frames that are sent one after the
+// other, FrameInterval apart. A frame has the positions of all fingers that
+// touch the screen at that point in time. Fingers that are not in the previous
+// frame are put on the screen, fingers that are not in the next frame are
+// lifted. After the last frame all fingers are lifted.
+//
+// Use the ...Gesture functions to create common gestures and PerformGesture to
+// send them. Events gives you the input that a gesture generates, e.g. to
+// check it in a test.
+type Gesture struct {
+	Frames        [][]TouchPoint
+	FrameInterval time.Duration
+}
+
+// gestureFrameInterval is the time between two frames of the predefined
+// gestures. Windows cancels touch contacts that are not updated for a while,
+// so we keep sending frames even if the fingers do not move.
+const gestureFrameInterval = 10 * time.Millisecond
+
+// frameCount returns the number of frames for a gesture of the given duration,
+// at least 1.
+func frameCount(duration time.Duration) int {
+	n := int(duration / gestureFrameInterval)
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// TapGesture touches the screen at x,y briefly with one finger.
+func TapGesture(x, y int) Gesture {
+	return LongPressGesture(x, y, 50*time.Millisecond)
+}
+
+// LongPressGesture touches the screen at x,y with one finger for the given
+// duration.
+func LongPressGesture(x, y int, duration time.Duration) Gesture {
+	frames := make([][]TouchPoint, frameCount(duration))
+	for i := range frames {
+		frames[i] = []TouchPoint{{X: x, Y: y}}
+	}
+	return Gesture{Frames: frames, FrameInterval: gestureFrameInterval}
+}
+
+// SwipeGesture moves one finger from fromX,fromY to toX,toY in a straight
+// line, taking the given duration.
+func SwipeGesture(fromX, fromY, toX, toY int, duration time.Duration) Gesture {
+	n := frameCount(duration)
+	frames := make([][]TouchPoint, n+1)
+	for i := range frames {
+		t := float64(i) / float64(n)
+		frames[i] = []TouchPoint{{
+			X: fromX + round(float64(toX-fromX)*t),
+			Y: fromY + round(float64(toY-fromY)*t),
+		}}
+	}
+	return Gesture{Frames: frames, FrameInterval: gestureFrameInterval}
+}
+
+// PinchGesture puts two fingers on the screen, left and right of centerX,
+// centerY and fromDistance pixels apart, and moves them horizontally until
+// they are toDistance pixels apart, taking the given duration. Pinch in to
+// zoom out with fromDistance > toDistance and vice versa.
+func PinchGesture(centerX, centerY, fromDistance, toDistance int, duration time.Duration) Gesture {
+	n := frameCount(duration)
+	frames := make([][]TouchPoint, n+1)
+	for i := range frames {
+		t := float64(i) / float64(n)
+		half := (float64(fromDistance) + float64(toDistance-fromDistance)*t) / 2
+		frames[i] = []TouchPoint{
+			{Contact: 0, X: centerX - round(half), Y: centerY},
+			{Contact: 1, X: centerX + round(half), Y: centerY},
+		}
+	}
+	return Gesture{Frames: frames, FrameInterval: gestureFrameInterval}
+}
+
+// RotateGesture puts two fingers on the screen, on opposite sides of a circle
+// around centerX,centerY with the given radius, starting left and right of
+// the center, and moves them along the circle by the given angle, taking the
+// given duration. Positive degrees rotate clockwise on the screen.
+func RotateGesture(centerX, centerY, radius int, degrees float64, duration time.Duration) Gesture {
+	n := frameCount(duration)
+	frames := make([][]TouchPoint, n+1)
+	for i := range frames {
+		angle := degrees * math.Pi / 180 * float64(i) / float64(n)
+		dx := float64(radius) * math.Cos(angle)
+		// Screen y goes down, so a positive angle rotates clockwise.
+		dy := float64(radius) * math.Sin(angle)
+		frames[i] = []TouchPoint{
+			{Contact: 0, X: centerX - round(dx), Y: centerY - round(dy)},
+			{Contact: 1, X: centerX + round(dx), Y: centerY + round(dy)},
+		}
+	}
+	return Gesture{Frames: frames, FrameInterval: gestureFrameInterval}
+}
+
+// Events returns the touch input for each frame of the gesture. The last
+// entry lifts all remaining fingers.
+func (g Gesture) Events() [][]InputEvent {
+	var result [][]InputEvent
+	down := make(map[int]TouchPoint)
+	for _, frame := range g.Frames {
+		var events []InputEvent
+		inFrame := make(map[int]bool)
+		for _, p := range frame {
+			inFrame[p.Contact] = true
+		}
+		for _, p := range sortedTouches(down) {
+			if !inFrame[p.Contact] {
+				events = append(events, InputEvent{Type: InputTouchUp, Contact: p.Contact, X: p.X, Y: p.Y})
+				delete(down, p.Contact)
+			}
+		}
+		for _, p := range frame {
+			typ := InputTouchMove
+			if _, ok := down[p.Contact]; !ok {
+				typ = InputTouchDown
+			}
+			events = append(events, InputEvent{Type: typ, Contact: p.Contact, X: p.X, Y: p.Y})
+			down[p.Contact] = p
+		}
+		result = append(result, events)
+	}
+	return append(result, liftTouches(down))
+}
+
+// liftTouches returns the events that lift the given fingers.
+func liftTouches(touches map[int]TouchPoint) []InputEvent {
+	var up []InputEvent
+	for _, p := range sortedTouches(touches) {
+		up = append(up, InputEvent{Type: InputTouchUp, Contact: p.Contact, X: p.X, Y: p.Y})
+	}
+	return up
+}
+
+// sortedTouches returns the touch points ordered by contact number, which
+// makes Events deterministic.
+func sortedTouches(touches map[int]TouchPoint) []TouchPoint {
+	var sorted []TouchPoint
+	for contact := 0; contact < MaxTouchContacts; contact++ {
+		if p, ok := touches[contact]; ok {
+			sorted = append(sorted, p)
+		}
+	}
+	return sorted
+}
+
+// PerformGesture sends the touch input of the given gesture, one frame per
+// FrameInterval. If sending fails, all fingers that might still be down are
+// lifted.
+func PerformGesture(g Gesture) error {
+	for _, frame := range g.Frames {
+		for _, p := range frame {
+			if p.Contact < 0 || p.Contact >= MaxTouchContacts {
+				return fmt.Errorf("invalid touch contact %d", p.Contact)
+			}
+		}
+	}
+
+	if len(g.Frames) == 0 {
+		return nil
+	}
+
+	d := driver()
+	down := make(map[int]TouchPoint)
+	for i, events := range g.Events() {
+		if i > 0 {
+			time.Sleep(g.FrameInterval)
+		}
+		err := d.Inject(events...)
+		for _, e := range events {
+			// If the frame failed, the fingers in it might be down or not, so
+			// we keep them all as down.
+			if e.Type != InputTouchUp {
+				down[e.Contact] = TouchPoint{Contact: e.Contact, X: e.X, Y: e.Y}
+			} else if err == nil {
+				delete(down, e.Contact)
+			}
+		}
+		if err != nil {
+			// Lift the fingers one at a time, so one that fails does not keep
+			// the others on the screen.
+			for _, up := range liftTouches(down) {
+				d.Inject(up)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Tap touches the screen at x,y briefly with one finger.
+func Tap(x, y int) error {
+	return PerformGesture(TapGesture(x, y))
+}
+
+// LongPress touches the screen at x,y with one finger for the given duration.
+func LongPress(x, y int, duration time.Duration) error {
+	return PerformGesture(LongPressGesture(x, y, duration))
+}
+
+// Swipe moves one finger over the screen, see SwipeGesture.
+func Swipe(fromX, fromY, toX, toY int, duration time.Duration) error {
+	return PerformGesture(SwipeGesture(fromX, fromY, toX, toY, duration))
+}
+
+// Pinch moves two fingers towards or away from each other, see PinchGesture.
+func Pinch(centerX, centerY, fromDistance, toDistance int, duration time.Duration) error {
+	return PerformGesture(PinchGesture(centerX, centerY, fromDistance, toDistance, duration))
+}
+
+// Rotate moves two fingers around a center, see RotateGesture.
+func Rotate(centerX, centerY, radius int, degrees float64, duration time.Duration) error {
+	return PerformGesture(RotateGesture(centerX, centerY, radius, degrees, duration))
+}
+
+// touchFrames splits touch events into frames, which are sent to the system
+// at the same time. A frame ends before an event for a contact that already
+// has an event in the frame.
+func touchFrames(events []InputEvent) [][]InputEvent {
+	var frames [][]InputEvent
+	var frame []InputEvent
+	inFrame := make(map[int]bool)
+	for _, e := range events {
+		if inFrame[e.Contact] {
+			frames = append(frames, frame)
+			frame = nil
+			inFrame = make(map[int]bool)
+		}
+		frame = append(frame, e)
+		inFrame[e.Contact] = true
+	}
+	if len(frame) > 0 {
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func isTouch(e InputEvent) bool {
+	return e.Type == InputTouchDown || e.Type == InputTouchMove || e.Type == InputTouchUp
+}
@@ -0,0 +1,164 @@
+package auto
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// These are the multi-touch codes from linux/input-event-codes.h.
+const (
+	absMTSlot       = 0x2F
+	absMTPositionX  = 0x35
+	absMTPositionY  = 0x36
+	absMTTrackingID = 0x39
+
+	btnTouch = 0x14A
+
+	inputPropDirect = 0x01
+	uiSetPropBit    = 0x4004556E
+)
+
+// touchscreen is a virtual multi-touch screen created with uinput. X11 has no
+// way to fake touch input, so we use it with and without an X server.
+var touchscreen struct {
+	sync.Mutex
+	device        *os.File
+	width, height int
+	// contacts maps the fingers that are down to their slot's tracking ID.
+	contacts map[int]int32
+	nextID   int32
+	touching bool
+}
+
+// injectTouch sends the given touch events, frame by frame.
+func injectTouch(events []InputEvent) error {
+	touchscreen.Lock()
+	defer touchscreen.Unlock()
+
+	if touchscreen.device == nil {
+		if err := createTouchscreen(); err != nil {
+			return err
+		}
+	}
+	for _, frame := range touchFrames(events) {
+		if err := injectTouchFrame(frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createTouchscreen creates the virtual touch screen. It spans the whole
+// screen, so we need to know the screen size. touchscreen must be locked.
+func createTouchscreen() error {
+	width, height, ok := screenSize()
+	if !ok {
+		return errors.New("cannot create a touch screen because the screen size is unknown")
+	}
+	var absMax [64]int32
+	absMax[absX] = int32(width - 1)
+	absMax[absY] = int32(height - 1)
+	absMax[absMTSlot] = MaxTouchContacts - 1
+	absMax[absMTPositionX] = int32(width - 1)
+	absMax[absMTPositionY] = int32(height - 1)
+	absMax[absMTTrackingID] = 0xFFFF
+	device, err := createUinputDevice("auto virtual touch screen", func(f *os.File) error {
+		// A direct input device is a touch screen, not a touch pad.
+		if err := ioctl(f, uiSetPropBit, inputPropDirect); err != nil {
+			return err
+		}
+		if err := ioctl(f, uiSetEvBit, evKey); err != nil {
+			return err
+		}
+		if err := ioctl(f, uiSetKeyBit, btnTouch); err != nil {
+			return err
+		}
+		if err := ioctl(f, uiSetEvBit, evAbs); err != nil {
+			return err
+		}
+		for _, code := range []uintptr{absX, absY, absMTSlot, absMTPositionX, absMTPositionY, absMTTrackingID} {
+			if err := ioctl(f, uiSetAbsBit, code); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil, &absMax)
+	if err != nil {
+		return err
+	}
+
+	// User space needs some time to pick up new devices. Events that are
+	// written before that are lost.
+	time.Sleep(200 * time.Millisecond)
+
+	touchscreen.device = device
+	touchscreen.width, touchscreen.height = width, height
+	touchscreen.contacts = make(map[int]int32)
+	return nil
+}
+
+// injectTouchFrame writes the events of one frame in the multi-touch protocol
+// type B, where every finger has its own slot. touchscreen must be locked.
+func injectTouchFrame(frame []InputEvent) error {
+	for _, e := range frame {
+		_, down := touchscreen.contacts[e.Contact]
+		if e.Type == InputTouchDown && down {
+			return fmt.Errorf("touch contact %d is already down", e.Contact)
+		}
+		if e.Type != InputTouchDown && !down {
+			return fmt.Errorf("touch contact %d is not down", e.Contact)
+		}
+	}
+
+	var events []evdevEvent
+	for _, e := range frame {
+		events = append(events, evdevEvent{typ: evAbs, code: absMTSlot, value: int32(e.Contact)})
+		switch e.Type {
+		case InputTouchDown:
+			id := touchscreen.nextID
+			touchscreen.nextID = (touchscreen.nextID + 1) & 0xFFFF
+			touchscreen.contacts[e.Contact] = id
+			events = append(events, evdevEvent{typ: evAbs, code: absMTTrackingID, value: id})
+		case InputTouchUp:
+			delete(touchscreen.contacts, e.Contact)
+			events = append(events, evdevEvent{typ: evAbs, code: absMTTrackingID, value: -1})
+			continue
+		}
+		x := int32(clamp(e.X, 0, touchscreen.width-1))
+		y := int32(clamp(e.Y, 0, touchscreen.height-1))
+		events = append(events,
+			evdevEvent{typ: evAbs, code: absMTPositionX, value: x},
+			evdevEvent{typ: evAbs, code: absMTPositionY, value: y},
+		)
+		// Programs that do not understand multi-touch follow the first
+		// finger that touches the screen.
+		if e.Contact == firstTouch() {
+			events = append(events,
+				evdevEvent{typ: evAbs, code: absX, value: x},
+				evdevEvent{typ: evAbs, code: absY, value: y},
+			)
+		}
+	}
+
+	touching := len(touchscreen.contacts) > 0
+	if touching != touchscreen.touching {
+		events = append(events, keyValue(btnTouch, touching))
+		touchscreen.touching = touching
+	}
+	events = append(events, syn)
+	return writeEvents(touchscreen.device, events...)
+}
+
+// firstTouch returns the lowest contact that is down, or -1 if there is none.
+// touchscreen must be locked.
+func firstTouch() int {
+	for contact := 0; contact < MaxTouchContacts; contact++ {
+		if _, ok := touchscreen.contacts[contact]; ok {
+			return contact
+		}
+	}
+	return -1
+}
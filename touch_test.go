@@ -0,0 +1,173 @@
+package auto
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func touchDown(contact, x, y int) InputEvent {
+	return InputEvent{Type: InputTouchDown, Contact: contact, X: x, Y: y}
+}
+
+func touchMove(contact, x, y int) InputEvent {
+	return InputEvent{Type: InputTouchMove, Contact: contact, X: x, Y: y}
+}
+
+func touchUp(contact, x, y int) InputEvent {
+	return InputEvent{Type: InputTouchUp, Contact: contact, X: x, Y: y}
+}
+
+func TestGestureEvents(t *testing.T) {
+	g := Gesture{Frames: [][]TouchPoint{
+		{{Contact: 0, X: 1, Y: 1}},
+		{{Contact: 0, X: 2, Y: 2}, {Contact: 1, X: 5, Y: 5}},
+		{{Contact: 1, X: 6, Y: 6}},
+	}}
+	want := [][]InputEvent{
+		{touchDown(0, 1, 1)},
+		{touchMove(0, 2, 2), touchDown(1, 5, 5)},
+		{touchUp(0, 2, 2), touchMove(1, 6, 6)},
+		{touchUp(1, 6, 6)},
+	}
+	if got := g.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %v\nwant %v", got, want)
+	}
+
+	if got := (Gesture{}).Events(); len(got) != 1 || len(got[0]) != 0 {
+		t.Errorf("an empty gesture should only have an empty final frame but has %v", got)
+	}
+}
+
+func TestTapGesture(t *testing.T) {
+	events := TapGesture(10, 20).Events()
+	if len(events) < 2 {
+		t.Fatalf("got %d frames", len(events))
+	}
+	if want := []InputEvent{touchDown(0, 10, 20)}; !reflect.DeepEqual(events[0], want) {
+		t.Errorf("first frame is %v, want %v", events[0], want)
+	}
+	for _, frame := range events[1 : len(events)-1] {
+		if want := []InputEvent{touchMove(0, 10, 20)}; !reflect.DeepEqual(frame, want) {
+			t.Errorf("frame is %v, want %v", frame, want)
+		}
+	}
+	if want := []InputEvent{touchUp(0, 10, 20)}; !reflect.DeepEqual(events[len(events)-1], want) {
+		t.Errorf("last frame is %v, want %v", events[len(events)-1], want)
+	}
+}
+
+func TestSwipeGesture(t *testing.T) {
+	g := SwipeGesture(0, 100, 200, 0, 100*time.Millisecond)
+	if len(g.Frames) != 11 {
+		t.Errorf("got %d frames, want 11", len(g.Frames))
+	}
+	first, last := g.Frames[0][0], g.Frames[len(g.Frames)-1][0]
+	if first != (TouchPoint{X: 0, Y: 100}) || last != (TouchPoint{X: 200, Y: 0}) {
+		t.Errorf("swipe goes from %v to %v", first, last)
+	}
+	if mid := g.Frames[5][0]; mid != (TouchPoint{X: 100, Y: 50}) {
+		t.Errorf("middle of the swipe is %v", mid)
+	}
+}
+
+func TestPinchGesture(t *testing.T) {
+	g := PinchGesture(100, 50, 200, 40, 50*time.Millisecond)
+	want := []TouchPoint{{Contact: 0, X: 0, Y: 50}, {Contact: 1, X: 200, Y: 50}}
+	if !reflect.DeepEqual(g.Frames[0], want) {
+		t.Errorf("first frame is %v, want %v", g.Frames[0], want)
+	}
+	want = []TouchPoint{{Contact: 0, X: 80, Y: 50}, {Contact: 1, X: 120, Y: 50}}
+	if last := g.Frames[len(g.Frames)-1]; !reflect.DeepEqual(last, want) {
+		t.Errorf("last frame is %v, want %v", last, want)
+	}
+}
+
+func TestRotateGesture(t *testing.T) {
+	g := RotateGesture(100, 100, 50, 90, 50*time.Millisecond)
+	want := []TouchPoint{{Contact: 0, X: 50, Y: 100}, {Contact: 1, X: 150, Y: 100}}
+	if !reflect.DeepEqual(g.Frames[0], want) {
+		t.Errorf("first frame is %v, want %v", g.Frames[0], want)
+	}
+	// Clockwise on the screen, the right finger moves down.
+	want = []TouchPoint{{Contact: 0, X: 100, Y: 50}, {Contact: 1, X: 100, Y: 150}}
+	if last := g.Frames[len(g.Frames)-1]; !reflect.DeepEqual(last, want) {
+		t.Errorf("last frame is %v, want %v", last, want)
+	}
+}
+
+func TestPerformGestureRejectsInvalidContacts(t *testing.T) {
+	d := useFakeDriver(t)
+	g := Gesture{Frames: [][]TouchPoint{{{Contact: MaxTouchContacts}}}}
+	if err := PerformGesture(g); err == nil {
+		t.Error("invalid contact was accepted")
+	}
+	if len(d.injected) != 0 {
+		t.Errorf("input was injected: %v", d.injected)
+	}
+}
+
+func TestPerformGestureLiftsAllFingersOnError(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames [][]TouchPoint
+		failAt int
+		want   [][]InputEvent
+	}{
+		{
+			name: "finger added in the failing frame",
+			frames: [][]TouchPoint{
+				{{Contact: 0, X: 1, Y: 1}},
+				{{Contact: 0, X: 2, Y: 2}, {Contact: 1, X: 5, Y: 5}},
+				{{Contact: 0, X: 3, Y: 3}, {Contact: 1, X: 6, Y: 6}},
+			},
+			failAt: 2,
+			want: [][]InputEvent{
+				{touchDown(0, 1, 1)},
+				{touchUp(0, 2, 2)},
+				{touchUp(1, 5, 5)},
+			},
+		},
+		{
+			name: "finger lifted in the failing frame",
+			frames: [][]TouchPoint{
+				{{Contact: 0, X: 1, Y: 1}, {Contact: 2, X: 9, Y: 9}},
+				{{Contact: 1, X: 5, Y: 5}},
+			},
+			failAt: 2,
+			want: [][]InputEvent{
+				{touchDown(0, 1, 1), touchDown(2, 9, 9)},
+				{touchUp(0, 1, 1)},
+				{touchUp(1, 5, 5)},
+				{touchUp(2, 9, 9)},
+			},
+		},
+		{
+			name: "fingers that stayed down before the failing frame",
+			frames: [][]TouchPoint{
+				{{Contact: 0, X: 1, Y: 1}},
+				{{Contact: 0, X: 1, Y: 1}, {Contact: 1, X: 5, Y: 5}},
+				{{Contact: 1, X: 6, Y: 6}},
+			},
+			failAt: 3,
+			want: [][]InputEvent{
+				{touchDown(0, 1, 1)},
+				{touchMove(0, 1, 1), touchDown(1, 5, 5)},
+				{touchUp(0, 1, 1)},
+				{touchUp(1, 6, 6)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := useFakeDriver(t)
+			d.failAt = tt.failAt
+			if err := PerformGesture(Gesture{Frames: tt.frames}); err == nil {
+				t.Fatal("the injection error was not returned")
+			}
+			if !reflect.DeepEqual(d.injected, tt.want) {
+				t.Errorf("\ngot  %v\nwant %v", d.injected, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package auto
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/gonutz/w32/v2"
+)
+
+var (
+	initializeTouchInjection = user32.NewProc("InitializeTouchInjection")
+	injectTouchInput         = user32.NewProc("InjectTouchInput")
+)
+
+// pointerInfo is POINTER_INFO from the Windows API.
+type pointerInfo struct {
+	pointerType           uint32
+	pointerID             uint32
+	frameID               uint32
+	pointerFlags          uint32
+	sourceDevice          uintptr
+	hwndTarget            uintptr
+	ptPixelLocation       w32.POINT
+	ptHimetricLocation    w32.POINT
+	ptPixelLocationRaw    w32.POINT
+	ptHimetricLocationRaw w32.POINT
+	time                  uint32
+	historyCount          uint32
+	inputData             int32
+	keyStates             uint32
+	performanceCount      uint64
+	buttonChangeType      int32
+	// The C struct is 8 byte aligned because of performanceCount. Go only
+	// aligns it to 4 bytes on 32 bit systems, so we pad it ourselves.
+	_ uint32
+}
+
+// pointerTouchInfo is POINTER_TOUCH_INFO from the Windows API.
+type pointerTouchInfo struct {
+	pointerInfo  pointerInfo
+	touchFlags   uint32
+	touchMask    uint32
+	rcContact    w32.RECT
+	rcContactRaw w32.RECT
+	orientation  uint32
+	pressure     uint32
+}
+
+const (
+	ptTouch = 2
+
+	pointerFlagInRange   = 0x00000002
+	pointerFlagInContact = 0x00000004
+	pointerFlagDown      = 0x00010000
+	pointerFlagUpdate    = 0x00020000
+	pointerFlagUp        = 0x00040000
+
+	touchMaskContactArea = 0x00000001
+	touchMaskOrientation = 0x00000002
+	touchMaskPressure    = 0x00000004
+
+	touchFeedbackDefault = 1
+)
+
+// touch holds the fingers that are currently on the touch screen. Windows
+// wants every frame to contain all fingers, even those that did not move.
+var touch struct {
+	sync.Mutex
+	initialized bool
+	contacts    map[int]w32.POINT
+}
+
+// injectTouch sends the given touch events, frame by frame.
+func injectTouch(events []InputEvent) error {
+	touch.Lock()
+	defer touch.Unlock()
+
+	if !touch.initialized {
+		ok, _, err := initializeTouchInjection.Call(MaxTouchContacts, touchFeedbackDefault)
+		if ok == 0 {
+			return fmt.Errorf("InitializeTouchInjection failed: %w", err)
+		}
+		touch.initialized = true
+		touch.contacts = make(map[int]w32.POINT)
+	}
+
+	for _, frame := range touchFrames(events) {
+		if err := injectTouchFrame(frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func injectTouchFrame(frame []InputEvent) error {
+	for _, e := range frame {
+		_, down := touch.contacts[e.Contact]
+		if e.Type == InputTouchDown && down {
+			return fmt.Errorf("touch contact %d is already down", e.Contact)
+		}
+		if e.Type != InputTouchDown && !down {
+			return fmt.Errorf("touch contact %d is not down", e.Contact)
+		}
+	}
+
+	flags := make(map[int]uint32)
+	for _, e := range frame {
+		switch e.Type {
+		case InputTouchDown:
+			flags[e.Contact] = pointerFlagDown | pointerFlagInRange | pointerFlagInContact
+			touch.contacts[e.Contact] = w32.POINT{X: int32(e.X), Y: int32(e.Y)}
+		case InputTouchMove:
+			flags[e.Contact] = pointerFlagUpdate | pointerFlagInRange | pointerFlagInContact
+			touch.contacts[e.Contact] = w32.POINT{X: int32(e.X), Y: int32(e.Y)}
+		case InputTouchUp:
+			flags[e.Contact] = pointerFlagUp
+		}
+	}
+
+	var infos []pointerTouchInfo
+	for contact, p := range touch.contacts {
+		f, ok := flags[contact]
+		if !ok {
+			// Fingers without input in this frame stay where they are.
+			f = pointerFlagUpdate | pointerFlagInRange | pointerFlagInContact
+		}
+		const contactSize = 2
+		infos = append(infos, pointerTouchInfo{
+			pointerInfo: pointerInfo{
+				pointerType:     ptTouch,
+				pointerID:       uint32(contact),
+				pointerFlags:    f,
+				ptPixelLocation: p,
+			},
+			touchMask: touchMaskContactArea | touchMaskOrientation | touchMaskPressure,
+			rcContact: w32.RECT{
+				Left:   p.X - contactSize,
+				Top:    p.Y - contactSize,
+				Right:  p.X + contactSize,
+				Bottom: p.Y + contactSize,
+			},
+			orientation: 90,
+			pressure:    32000,
+		})
+	}
+	for contact, f := range flags {
+		if f == pointerFlagUp {
+			delete(touch.contacts, contact)
+		}
+	}
+	if len(infos) == 0 {
+		return nil
+	}
+
+	ok, _, err := injectTouchInput.Call(
+		uintptr(len(infos)),
+		uintptr(unsafe.Pointer(&infos[0])),
+	)
+	if ok == 0 {
+		if errno, isErrno := err.(syscall.Errno); isErrno && errno == 0 {
+			return errors.New("InjectTouchInput failed")
+		}
+		return fmt.Errorf("InjectTouchInput failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package auto
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf16"
+)
+
+// TypeMode decides how TypeWithOptions generates the characters of a text.
+type TypeMode int
+
+// These are the available TypeModes.
+const (
+	// TypeAuto uses the most reliable mode of the platform. On Windows that
+	// is TypeUnicode. On X11 characters on the keyboard layout are typed with
+	// their keys and all others are mapped to spare key codes. Without an X
+	// server it is TypeLayout.
+	TypeAuto TypeMode = iota
+	// TypeUnicode types the characters themselves, not the keys that produce
+	// them. On Windows this uses KEYEVENTF_UNICODE, characters outside the
+	// Basic Multilingual Plane, like emoji, are sent as UTF-16 surrogate
+	// pairs. Applications that read key codes instead of characters, e.g.
+	// games, do not see this input. On X11 every character is mapped to a
+	// spare key code unless it is on the keyboard layout already.
+	TypeUnicode
+	// TypeAltNumpad holds Alt and types the character's decimal code in the
+	// ANSI code page on the numpad. This only exists on Windows and
+	// characters that are not in the code page cannot be typed.
+	TypeAltNumpad
+	// TypeLayout presses the keys that produce the characters on the current
+	// keyboard layout, see KeysForRune, which also explains the limits on
+	// X11. Characters that are not on the layout cannot be typed, unless
+	// TypeOptions.Fallback is set.
+	TypeLayout
+)
+
+// TypeOptions configure TypeWithOptions.
+type TypeOptions struct {
+	// Mode decides how the characters are generated, see TypeMode.
+	Mode TypeMode
+	// Delay is the time to sleep after each character.
+	Delay time.Duration
+	// Fallback only applies to TypeLayout. If it is set, characters that are
+	// not on the keyboard layout are typed in TypeAuto mode instead of
+	// failing.
+	Fallback bool
+}
+
+// TypeWithOptions writes the given text, see TypeOptions.
+//
+// In every mode, line breaks are typed as the Enter key, '\t' as the Tab key
+// and '\b' as the Backspace key.
+func TypeWithOptions(s string, options TypeOptions) error {
+	if options.Mode < TypeAuto || options.Mode > TypeLayout {
+		return fmt.Errorf("invalid type mode %d", options.Mode)
+	}
+	if options.Mode == TypeLayout {
+		return typeLayout(unifyLineBreaks(s), options)
+	}
+	return driver().Type(unifyLineBreaks(s), options)
+}
+
+// ErrNotOnLayout is returned by KeysForRune for characters that no keys
+// produce on the current keyboard layout.
+var ErrNotOnLayout = errors.New("auto: the character is not on the keyboard layout")
+
+// KeysForRune returns the key events that type r on the current keyboard
+// layout, e.g. for 'A' on a US layout: KeyShift down, KeyA down, KeyA up,
+// KeyShift up. Send them with Inject or InputSequence.Add.
+//
+// On Windows the layout is the one of the foreground window. Characters that
+// need AltGr are typed with Ctrl+Alt and characters that need a dead key are
+// typed as the dead key followed by the base key, e.g. '^' and 'e' for 'ê'.
+//
+// On X11 the events can only name keys that have a Key... constant. Characters
+// that these keys produce alone, with Shift or with AltGr, which is
+// KeyRightAlt, are found. Dead keys are not supported and neither are keys
+// without a constant, e.g. the ö on a German layout. TypeLayout with Fallback
+// still types such characters with their own keys if they have one, see
+// TypeAuto, and with a spare key code otherwise. Without an X server the
+// layout is assumed to be US.
+//
+// For characters that are not on the layout the returned error wraps
+// ErrNotOnLayout.
+func KeysForRune(r rune) ([]InputEvent, error) {
+	return driver().KeysForRune(r)
+}
+
+// typeLayout types s in TypeLayout mode, see TypeOptions.Fallback.
+func typeLayout(s string, options TypeOptions) error {
+	d := driver()
+	for _, r := range s {
+		var events []InputEvent
+		if key, ok := controlKeys[r]; ok {
+			events = chordEvents(key)
+		} else {
+			var err error
+			events, err = d.KeysForRune(r)
+			if errors.Is(err, ErrNotOnLayout) && options.Fallback {
+				err = d.Type(string(r), TypeOptions{Delay: options.Delay})
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := d.Inject(events...); err != nil {
+			return err
+		}
+		time.Sleep(options.Delay)
+	}
+	return nil
+}
+
+// chordEvents presses the given keys in order and releases them in reverse
+// order.
+func chordEvents(keys ...uint16) []InputEvent {
+	events := make([]InputEvent, 0, 2*len(keys))
+	for _, key := range keys {
+		events = append(events, keyDown(key))
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		events = append(events, keyUp(keys[i]))
+	}
+	return events
+}
+
+// keystroke is a single key press or release that typing a text generates.
+type keystroke struct {
+	// key is the virtual key code, see the Key... constants. It is 0 for
+	// Unicode input.
+	key uint16
+	// unit is the UTF-16 code unit of Unicode input.
+	unit uint16
+	up   bool
+	// scanCode sends the key only as its hardware scan code. Alt+Numpad
+	// input needs this, applications ignore it otherwise.
+	scanCode bool
+}
+
+// ansiEncoder returns the byte that encodes r in the ANSI code page.
+type ansiEncoder func(r rune) (b byte, ok bool)
+
+// textKeystrokes translates the text into keystrokes, a list for every
+// character, in the given mode. TypeAuto must be resolved to a concrete mode
+// before. ansi is only used in TypeAltNumpad mode.
+func textKeystrokes(s string, mode TypeMode, ansi ansiEncoder) ([][]keystroke, error) {
+	var result [][]keystroke
+	for _, r := range s {
+		var strokes []keystroke
+		if key, ok := controlKeys[r]; ok {
+			strokes = pressKeys(key)
+		} else {
+			switch mode {
+			case TypeUnicode:
+				strokes = unicodeKeystrokes(r)
+			case TypeAltNumpad:
+				var err error
+				strokes, err = altNumpadKeystrokes(r, ansi)
+				if err != nil {
+					return nil, err
+				}
+			default:
+				return nil, fmt.Errorf("invalid type mode %d", mode)
+			}
+		}
+		result = append(result, strokes)
+	}
+	return result, nil
+}
+
+// controlKeys are characters that are typed with their keys in every mode.
+var controlKeys = map[rune]uint16{
+	'\r': KeyEnter,
+	'\t': KeyTab,
+	'\b': KeyBackspace,
+}
+
+// pressKeys presses the given keys in order and releases them in reverse
+// order.
+func pressKeys(keys ...uint16) []keystroke {
+	events := chordEvents(keys...)
+	strokes := make([]keystroke, len(events))
+	for i, e := range events {
+		strokes[i] = keystroke{key: e.Key, up: e.Type == InputKeyUp}
+	}
+	return strokes
+}
+
+// unicodeKeystrokes types r as Unicode input. Characters outside the Basic
+// Multilingual Plane become a surrogate pair, which must be sent together.
+func unicodeKeystrokes(r rune) []keystroke {
+	var strokes []keystroke
+	for _, unit := range utf16.Encode([]rune{r}) {
+		strokes = append(strokes,
+			keystroke{unit: unit},
+			keystroke{unit: unit, up: true},
+		)
+	}
+	return strokes
+}
+
+// altNumpadKeystrokes types r by holding Alt and typing 0 and its decimal
+// code on the numpad. With the leading 0, Windows uses the ANSI code page
+// instead of the OEM code page.
+func altNumpadKeystrokes(r rune, ansi ansiEncoder) ([]keystroke, error) {
+	code, ok := ansi(r)
+	if !ok {
+		return nil, fmt.Errorf("cannot type %q with Alt+Numpad, it is not in the ANSI code page", r)
+	}
+	strokes := []keystroke{{key: KeyAlt, scanCode: true}}
+	for _, digit := range "0" + strconv.Itoa(int(code)) {
+		num := uint16(KeyNum0 + digit - '0')
+		strokes = append(strokes,
+			keystroke{key: num, scanCode: true},
+			keystroke{key: num, up: true, scanCode: true},
+		)
+	}
+	return append(strokes, keystroke{key: KeyAlt, up: true, scanCode: true}), nil
+}
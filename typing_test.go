@@ -0,0 +1,104 @@
+package auto
+
+import (
+	"reflect"
+	"testing"
+)
+
+// latin1 is an ansiEncoder for a code page that only has the printable Latin-1
+// characters.
+func latin1(r rune) (byte, bool) {
+	if 0x20 <= r && r <= 0x7E || 0xA0 <= r && r <= 0xFF {
+		return byte(r), true
+	}
+	return 0, false
+}
+
+func TestUnicodeKeystrokes(t *testing.T) {
+	tests := []struct {
+		text string
+		want [][]keystroke
+	}{
+		{"a", [][]keystroke{{{unit: 'a'}, {unit: 'a', up: true}}}},
+		{"ß€", [][]keystroke{
+			{{unit: 'ß'}, {unit: 'ß', up: true}},
+			{{unit: '€'}, {unit: '€', up: true}},
+		}},
+		// Characters outside the Basic Multilingual Plane become a surrogate
+		// pair, both halves in the same list.
+		{"😀", [][]keystroke{{
+			{unit: 0xD83D}, {unit: 0xD83D, up: true},
+			{unit: 0xDE00}, {unit: 0xDE00, up: true},
+		}}},
+	}
+	for _, tt := range tests {
+		got, err := textKeystrokes(tt.text, TypeUnicode, latin1)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q:\ngot  %+v\nwant %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestControlKeystrokes(t *testing.T) {
+	tap := func(key uint16) []keystroke {
+		return []keystroke{{key: key}, {key: key, up: true}}
+	}
+	want := [][]keystroke{
+		tap(KeyEnter), tap(KeyEnter), tap(KeyEnter), tap(KeyTab), tap(KeyBackspace),
+	}
+	for _, mode := range []TypeMode{TypeUnicode, TypeAltNumpad} {
+		got, err := textKeystrokes(unifyLineBreaks("\r\n\n\r\t\b"), mode, latin1)
+		if err != nil {
+			t.Errorf("mode %d: unexpected error: %v", mode, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("mode %d:\ngot  %+v\nwant %+v", mode, got, want)
+		}
+	}
+}
+
+func TestAltNumpadKeystrokes(t *testing.T) {
+	// Alt+Numpad input only works with scan codes.
+	tap := func(key uint16) []keystroke {
+		return []keystroke{{key: key, scanCode: true}, {key: key, up: true, scanCode: true}}
+	}
+	var want []keystroke
+	want = append(want, keystroke{key: KeyAlt, scanCode: true})
+	want = append(want, tap(KeyNum0)...)
+	want = append(want, tap(KeyNum2)...)
+	want = append(want, tap(KeyNum2)...)
+	want = append(want, tap(KeyNum8)...)
+	want = append(want, keystroke{key: KeyAlt, up: true, scanCode: true})
+
+	got, err := textKeystrokes("ä", TypeAltNumpad, latin1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, [][]keystroke{want}) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+
+	for _, text := range []string{"€", "😀", "a€"} {
+		if _, err := textKeystrokes(text, TypeAltNumpad, latin1); err == nil {
+			t.Errorf("%q is not in the code page but there was no error", text)
+		}
+	}
+}
+
+func TestInvalidTypeModes(t *testing.T) {
+	for _, mode := range []TypeMode{TypeAuto, TypeLayout, -1, 99} {
+		if _, err := textKeystrokes("a", mode, latin1); err == nil {
+			t.Errorf("textKeystrokes accepted mode %d", mode)
+		}
+	}
+	for _, mode := range []TypeMode{-1, TypeLayout + 1} {
+		if err := TypeWithOptions("a", TypeOptions{Mode: mode}); err == nil {
+			t.Errorf("TypeWithOptions accepted mode %d", mode)
+		}
+	}
+}
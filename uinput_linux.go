@@ -0,0 +1,652 @@
+package auto
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// uinput lets programs create virtual input devices in the Linux kernel. Input
+// from these devices is handled like input from real hardware, which works no
+// matter which display server is running, if any. Writing to /dev/uinput
+// usually requires root or membership in the input group.
+
+// These are event types and codes from linux/input-event-codes.h.
+const (
+	evSyn = 0x00
+	evKey = 0x01
+	evRel = 0x02
+	evAbs = 0x03
+
+	synReport = 0
+
+	relX           = 0x00
+	relY           = 0x01
+	relHWheel      = 0x06
+	relWheel       = 0x08
+	relWheelHiRes  = 0x0B
+	relHWheelHiRes = 0x0C
+
+	absX = 0x00
+	absY = 0x01
+
+	btnLeft   = 0x110
+	btnRight  = 0x111
+	btnMiddle = 0x112
+	btnSide   = 0x113
+	btnExtra  = 0x114
+
+	keyLeftShift = 42
+)
+
+// These are the ioctl requests from linux/uinput.h.
+const (
+	uiDevCreate = 0x5501
+	uiSetEvBit  = 0x40045564
+	uiSetKeyBit = 0x40045565
+	uiSetRelBit = 0x40045566
+	uiSetAbsBit = 0x40045567
+)
+
+// wheelHiResPerTick is the high resolution wheel value of one wheel tick.
+const wheelHiResPerTick = 120
+
+// evdevButtons maps X11 pointer buttons to Linux button codes.
+var evdevButtons = map[byte]uint16{
+	xButtonLeft:    btnLeft,
+	xButtonRight:   btnRight,
+	xButtonMiddle:  btnMiddle,
+	xButtonBack:    btnSide,
+	xButtonForward: btnExtra,
+}
+
+// evdevKeys maps the Key... constants to Linux key codes. Keys that are
+// missing here have no Linux equivalent.
+var evdevKeys = map[uint16]uint16{
+	KeyCancel:             223, // KEY_CANCEL
+	KeyBackspace:          14,  // KEY_BACKSPACE
+	KeyTab:                15,  // KEY_TAB
+	KeyClear:              355, // KEY_CLEAR
+	KeyEnter:              28,  // KEY_ENTER
+	KeyShift:              42,  // KEY_LEFTSHIFT
+	KeyControl:            29,  // KEY_LEFTCTRL
+	KeyAlt:                56,  // KEY_LEFTALT
+	KeyPause:              119, // KEY_PAUSE
+	KeyCapsLock:           58,  // KEY_CAPSLOCK
+	KeyImeHangul:          122, // KEY_HANGEUL, same as KeyImeKana
+	KeyImeKanji:           123, // KEY_HANJA, same as KeyImeHanja
+	KeyEscape:             1,   // KEY_ESC
+	KeyImeConvert:         92,  // KEY_HENKAN
+	KeyImeNonConvert:      94,  // KEY_MUHENKAN
+	KeySpace:              57,  // KEY_SPACE
+	KeyPageUp:             104, // KEY_PAGEUP
+	KeyPageDown:           109, // KEY_PAGEDOWN
+	KeyEnd:                107, // KEY_END
+	KeyHome:               102, // KEY_HOME
+	KeyLeft:               105, // KEY_LEFT
+	KeyUp:                 103, // KEY_UP
+	KeyRight:              106, // KEY_RIGHT
+	KeyDown:               108, // KEY_DOWN
+	KeySelect:             353, // KEY_SELECT
+	KeyPrint:              210, // KEY_PRINT
+	KeyPrintScreen:        99,  // KEY_SYSRQ
+	KeyInsert:             110, // KEY_INSERT
+	KeyDelete:             111, // KEY_DELETE
+	KeyHelp:               138, // KEY_HELP
+	Key0:                  11,  // KEY_0
+	Key1:                  2,   // KEY_1
+	Key2:                  3,   // KEY_2
+	Key3:                  4,   // KEY_3
+	Key4:                  5,   // KEY_4
+	Key5:                  6,   // KEY_5
+	Key6:                  7,   // KEY_6
+	Key7:                  8,   // KEY_7
+	Key8:                  9,   // KEY_8
+	Key9:                  10,  // KEY_9
+	KeyA:                  30,  // KEY_A
+	KeyB:                  48,  // KEY_B
+	KeyC:                  46,  // KEY_C
+	KeyD:                  32,  // KEY_D
+	KeyE:                  18,  // KEY_E
+	KeyF:                  33,  // KEY_F
+	KeyG:                  34,  // KEY_G
+	KeyH:                  35,  // KEY_H
+	KeyI:                  23,  // KEY_I
+	KeyJ:                  36,  // KEY_J
+	KeyK:                  37,  // KEY_K
+	KeyL:                  38,  // KEY_L
+	KeyM:                  50,  // KEY_M
+	KeyN:                  49,  // KEY_N
+	KeyO:                  24,  // KEY_O
+	KeyP:                  25,  // KEY_P
+	KeyQ:                  16,  // KEY_Q
+	KeyR:                  19,  // KEY_R
+	KeyS:                  31,  // KEY_S
+	KeyT:                  20,  // KEY_T
+	KeyU:                  22,  // KEY_U
+	KeyV:                  47,  // KEY_V
+	KeyW:                  17,  // KEY_W
+	KeyX:                  45,  // KEY_X
+	KeyY:                  21,  // KEY_Y
+	KeyZ:                  44,  // KEY_Z
+	KeyLeftWin:            125, // KEY_LEFTMETA
+	KeyRightWin:           126, // KEY_RIGHTMETA
+	KeyApps:               127, // KEY_COMPOSE
+	KeySleep:              142, // KEY_SLEEP
+	KeyNum0:               82,  // KEY_KP0
+	KeyNum1:               79,  // KEY_KP1
+	KeyNum2:               80,  // KEY_KP2
+	KeyNum3:               81,  // KEY_KP3
+	KeyNum4:               75,  // KEY_KP4
+	KeyNum5:               76,  // KEY_KP5
+	KeyNum6:               77,  // KEY_KP6
+	KeyNum7:               71,  // KEY_KP7
+	KeyNum8:               72,  // KEY_KP8
+	KeyNum9:               73,  // KEY_KP9
+	KeyMultiply:           55,  // KEY_KPASTERISK
+	KeyPlus:               78,  // KEY_KPPLUS
+	KeySeparator:          121, // KEY_KPCOMMA
+	KeyMinus:              74,  // KEY_KPMINUS
+	KeyDecimal:            83,  // KEY_KPDOT
+	KeyDivide:             98,  // KEY_KPSLASH
+	KeyF1:                 59,  // KEY_F1
+	KeyF2:                 60,  // KEY_F2
+	KeyF3:                 61,  // KEY_F3
+	KeyF4:                 62,  // KEY_F4
+	KeyF5:                 63,  // KEY_F5
+	KeyF6:                 64,  // KEY_F6
+	KeyF7:                 65,  // KEY_F7
+	KeyF8:                 66,  // KEY_F8
+	KeyF9:                 67,  // KEY_F9
+	KeyF10:                68,  // KEY_F10
+	KeyF11:                87,  // KEY_F11
+	KeyF12:                88,  // KEY_F12
+	KeyF13:                183, // KEY_F13
+	KeyF14:                184, // KEY_F14
+	KeyF15:                185, // KEY_F15
+	KeyF16:                186, // KEY_F16
+	KeyF17:                187, // KEY_F17
+	KeyF18:                188, // KEY_F18
+	KeyF19:                189, // KEY_F19
+	KeyF20:                190, // KEY_F20
+	KeyF21:                191, // KEY_F21
+	KeyF22:                192, // KEY_F22
+	KeyF23:                193, // KEY_F23
+	KeyF24:                194, // KEY_F24
+	KeyNumLock:            69,  // KEY_NUMLOCK
+	KeyScrollLock:         70,  // KEY_SCROLLLOCK
+	KeyOemNecEqual:        117, // KEY_KPEQUAL, same as KeyOemFjJisho
+	KeyLeftShift:          42,  // KEY_LEFTSHIFT
+	KeyRightShift:         54,  // KEY_RIGHTSHIFT
+	KeyLeftControl:        29,  // KEY_LEFTCTRL
+	KeyRightControl:       97,  // KEY_RIGHTCTRL
+	KeyLeftAlt:            56,  // KEY_LEFTALT
+	KeyRightAlt:           100, // KEY_RIGHTALT
+	KeyBrowserBack:        158, // KEY_BACK
+	KeyBrowserForward:     159, // KEY_FORWARD
+	KeyBrowserRefresh:     173, // KEY_REFRESH
+	KeyBrowserStop:        128, // KEY_STOP
+	KeyBrowserSearch:      217, // KEY_SEARCH
+	KeyBrowserFavorites:   156, // KEY_BOOKMARKS
+	KeyBrowserHome:        172, // KEY_HOMEPAGE
+	KeyVolumeMute:         113, // KEY_MUTE
+	KeyVolumeDown:         114, // KEY_VOLUMEDOWN
+	KeyVolumeUp:           115, // KEY_VOLUMEUP
+	KeyMediaNextTrack:     163, // KEY_NEXTSONG
+	KeyMediaPreviousTrack: 165, // KEY_PREVIOUSSONG
+	KeyMediaStop:          166, // KEY_STOPCD
+	KeyMediaPlayPause:     164, // KEY_PLAYPAUSE
+	KeyLaunchMail:         155, // KEY_MAIL
+	KeyLaunchMediaSelect:  226, // KEY_MEDIA
+	KeyLaunchApp1:         157, // KEY_COMPUTER
+	KeyLaunchApp2:         140, // KEY_CALC
+	KeyOem1:               39,  // KEY_SEMICOLON
+	KeyOemPlus:            13,  // KEY_EQUAL
+	KeyOemComma:           51,  // KEY_COMMA
+	KeyOemMinus:           12,  // KEY_MINUS
+	KeyOemPeriod:          52,  // KEY_DOT
+	KeyOem2:               53,  // KEY_SLASH
+	KeyOem3:               41,  // KEY_GRAVE
+	KeyOem4:               26,  // KEY_LEFTBRACE
+	KeyOem5:               43,  // KEY_BACKSLASH
+	KeyOem6:               27,  // KEY_RIGHTBRACE
+	KeyOem7:               40,  // KEY_APOSTROPHE
+	KeyOem102:             86,  // KEY_102ND
+	KeyPlay:               207, // KEY_PLAY
+	KeyOemClear:           355, // KEY_CLEAR
+}
+
+// usKey is a key on the US keyboard layout, possibly with Shift held down.
+type usKey struct {
+	code  uint16
+	shift bool
+}
+
+// usLayout maps the characters on a US keyboard to the keys producing them.
+// Without an X server we do not know the keyboard layout, so we assume this
+// one.
+var usLayout = func() map[rune]usKey {
+	m := map[rune]usKey{
+		' ':  {code: 57},
+		'\r': {code: 28},
+		'\t': {code: 15},
+		'\b': {code: 14},
+	}
+	rows := []struct {
+		plain, shifted string
+		firstCode      uint16
+	}{
+		{"1234567890-=", "!@#$%^&*()_+", 2},
+		{"qwertyuiop[]", "QWERTYUIOP{}", 16},
+		{"asdfghjkl;'`", "ASDFGHJKL:\"~", 30},
+		{"\\", "|", 43},
+		{"zxcvbnm,./", "ZXCVBNM<>?", 44},
+	}
+	for _, row := range rows {
+		shifted := []rune(row.shifted)
+		for i, r := range []rune(row.plain) {
+			code := row.firstCode + uint16(i)
+			m[r] = usKey{code: code}
+			m[shifted[i]] = usKey{code: code, shift: true}
+		}
+	}
+	return m
+}()
+
+// uinputInjector generates input through two virtual devices: a keyboard with
+// mouse buttons, relative motion and wheels, and an absolute pointer. Mixing
+// absolute and relative axes in one device confuses most input stacks.
+type uinputInjector struct {
+	mu       sync.Mutex
+	keyboard *os.File
+	// pointer is nil if the screen size is unknown. Its axes span the
+	// screen, so we cannot create it without the size.
+	pointer       *os.File
+	width, height int
+	// The kernel does not know where the cursor is. We remember where we last
+	// moved it to.
+	x, y          int
+	positionKnown bool
+	// wheelX and wheelY are the high resolution wheel values that do not yet
+	// add up to a whole tick.
+	wheelX, wheelY int
+}
+
+func newUinputInjector() (*uinputInjector, error) {
+	keyboard, err := createUinputDevice("auto virtual keyboard and mouse", func(f *os.File) error {
+		if err := ioctl(f, uiSetEvBit, evKey); err != nil {
+			return err
+		}
+		for _, code := range evdevKeys {
+			if err := ioctl(f, uiSetKeyBit, uintptr(code)); err != nil {
+				return err
+			}
+		}
+		for _, code := range evdevButtons {
+			if err := ioctl(f, uiSetKeyBit, uintptr(code)); err != nil {
+				return err
+			}
+		}
+		if err := ioctl(f, uiSetEvBit, evRel); err != nil {
+			return err
+		}
+		for _, code := range []uintptr{relX, relY, relWheel, relHWheel, relWheelHiRes, relHWheelHiRes} {
+			if err := ioctl(f, uiSetRelBit, code); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &uinputInjector{keyboard: keyboard}
+
+	if width, height, ok := screenSize(); ok {
+		var absMax [64]int32
+		absMax[absX] = int32(width - 1)
+		absMax[absY] = int32(height - 1)
+		pointer, err := createUinputDevice("auto virtual absolute pointer", func(f *os.File) error {
+			// Input stacks only treat a device as a pointer if it has buttons.
+			if err := ioctl(f, uiSetEvBit, evKey); err != nil {
+				return err
+			}
+			if err := ioctl(f, uiSetKeyBit, btnLeft); err != nil {
+				return err
+			}
+			if err := ioctl(f, uiSetEvBit, evAbs); err != nil {
+				return err
+			}
+			if err := ioctl(f, uiSetAbsBit, absX); err != nil {
+				return err
+			}
+			return ioctl(f, uiSetAbsBit, absY)
+		}, nil, &absMax)
+		if err == nil {
+			u.pointer = pointer
+			u.width, u.height = width, height
+		}
+	}
+
+	// User space needs some time to pick up new devices. Events that are
+	// written before that are lost.
+	time.Sleep(200 * time.Millisecond)
+
+	return u, nil
+}
+
+// uinputUserDev is struct uinput_user_dev from linux/uinput.h.
+type uinputUserDev struct {
+	Name         [80]byte
+	BusType      uint16
+	Vendor       uint16
+	Product      uint16
+	Version      uint16
+	FFEffectsMax uint32
+	AbsMax       [64]int32
+	AbsMin       [64]int32
+	AbsFuzz      [64]int32
+	AbsFlat      [64]int32
+}
+
+// createUinputDevice creates a virtual input device. setup enables the event
+// types and codes of the device. absMin and absMax are the minimum and maximum
+// values of every absolute axis, they can be nil for devices without absolute
+// axes and absMin can be nil if all axes start at 0.
+func createUinputDevice(name string, setup func(*os.File) error, absMin, absMax *[64]int32) (*os.File, error) {
+	f, err := os.OpenFile("/dev/uinput", os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create virtual input devices: %w", err)
+	}
+
+	if err := setup(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("cannot set up virtual input device: %w", err)
+	}
+
+	const busVirtual = 0x06
+	dev := uinputUserDev{
+		BusType: busVirtual,
+		Version: 1,
+	}
+	copy(dev.Name[:len(dev.Name)-1], name)
+	if absMin != nil {
+		dev.AbsMin = *absMin
+	}
+	if absMax != nil {
+		dev.AbsMax = *absMax
+	}
+	if err := binary.Write(f, nativeEndian, &dev); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("cannot set up virtual input device: %w", err)
+	}
+
+	if err := ioctl(f, uiDevCreate, 0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("cannot create virtual input device: %w", err)
+	}
+
+	return f, nil
+}
+
+func ioctl(f *os.File, request, arg uintptr) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), request, arg)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// nativeEndian is the byte order of the kernel's structs.
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+// evdevEvent is a struct input_event without the time, which the kernel
+// fills in for us.
+type evdevEvent struct {
+	typ   uint16
+	code  uint16
+	value int32
+}
+
+// syn marks the end of a group of events that happen at the same time.
+var syn = evdevEvent{typ: evSyn, code: synReport}
+
+// writeEvents writes the given events to a virtual input device.
+func writeEvents(f *os.File, events ...evdevEvent) error {
+	// The time is a struct timeval which consists of two longs.
+	timeSize := 2 * strconv.IntSize / 8
+	buf := make([]byte, 0, len(events)*(timeSize+8))
+	for _, e := range events {
+		buf = append(buf, make([]byte, timeSize+8)...)
+		b := buf[len(buf)-8:]
+		nativeEndian.PutUint16(b[0:], e.typ)
+		nativeEndian.PutUint16(b[2:], e.code)
+		nativeEndian.PutUint32(b[4:], uint32(e.value))
+	}
+	_, err := f.Write(buf)
+	return err
+}
+
+func (u *uinputInjector) inject(events ...InputEvent) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for _, e := range events {
+		var err error
+		switch e.Type {
+		case InputKeyDown, InputKeyUp:
+			down := e.Type == InputKeyDown
+			if button, ok := keyButtons[e.Key]; ok {
+				err = writeEvents(u.keyboard, keyValue(evdevButtons[button], down), syn)
+				break
+			}
+			code, ok := evdevKeys[e.Key]
+			if !ok {
+				return fmt.Errorf("key %d has no Linux equivalent", e.Key)
+			}
+			err = writeEvents(u.keyboard, keyValue(code, down), syn)
+		case InputButtonDown, InputButtonUp:
+			code, ok := evdevButtons[xButtons[e.Button]]
+			if !ok {
+				return fmt.Errorf("unknown mouse button %d", e.Button)
+			}
+			err = writeEvents(u.keyboard, keyValue(code, e.Type == InputButtonDown), syn)
+		case InputMoveTo, InputMoveBy:
+			if e.Type == InputMoveBy && !u.positionKnown {
+				// Relative motion might be accelerated, but without a known
+				// position it is all we can do.
+				err = writeEvents(u.keyboard,
+					evdevEvent{typ: evRel, code: relX, value: int32(e.X)},
+					evdevEvent{typ: evRel, code: relY, value: int32(e.Y)},
+					syn,
+				)
+			} else {
+				x, y := e.X, e.Y
+				if e.Type == InputMoveBy {
+					x, y = u.x+x, u.y+y
+				}
+				err = u.moveTo(x, y)
+			}
+		case InputMoveRaw:
+			err = writeEvents(u.keyboard,
+				evdevEvent{typ: evRel, code: relX, value: int32(e.X)},
+				evdevEvent{typ: evRel, code: relY, value: int32(e.Y)},
+				syn,
+			)
+			// With acceleration we cannot know where the cursor ends up.
+			u.positionKnown = false
+		case InputWheel:
+			err = u.moveWheel(e.WheelX, e.WheelY)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveTo moves the absolute pointer. Its axes span the screen of the size we
+// found in the kernel's display information.
+func (u *uinputInjector) moveTo(x, y int) error {
+	if u.pointer == nil {
+		return errors.New("cannot move the mouse to an absolute position because the screen size is unknown")
+	}
+	x = clamp(x, 0, u.width-1)
+	y = clamp(y, 0, u.height-1)
+	err := writeEvents(u.pointer,
+		evdevEvent{typ: evAbs, code: absX, value: int32(x)},
+		evdevEvent{typ: evAbs, code: absY, value: int32(y)},
+		syn,
+	)
+	if err != nil {
+		return err
+	}
+	u.x, u.y = x, y
+	u.positionKnown = true
+	return nil
+}
+
+func keyValue(code uint16, down bool) evdevEvent {
+	e := evdevEvent{typ: evKey, code: code}
+	if down {
+		e.value = 1
+	}
+	return e
+}
+
+func (u *uinputInjector) mousePosition() (x, y int, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if !u.positionKnown {
+		return 0, 0, errors.New("the mouse position is unknown until the mouse is moved to a screen position")
+	}
+	return u.x, u.y, nil
+}
+
+// moveWheel sends high resolution wheel events, which support fractional
+// ticks. Older programs only understand the regular wheel events, which we
+// send every time the high resolution values add up to a whole tick.
+func (u *uinputInjector) moveWheel(dx, dy float64) error {
+	hiX := int(math.Round(dx * wheelHiResPerTick))
+	hiY := int(math.Round(dy * wheelHiResPerTick))
+	u.wheelX += hiX
+	u.wheelY += hiY
+	ticksX := u.wheelX / wheelHiResPerTick
+	ticksY := u.wheelY / wheelHiResPerTick
+	u.wheelX -= ticksX * wheelHiResPerTick
+	u.wheelY -= ticksY * wheelHiResPerTick
+
+	var events []evdevEvent
+	if hiY != 0 {
+		events = append(events, evdevEvent{typ: evRel, code: relWheelHiRes, value: int32(hiY)})
+	}
+	if ticksY != 0 {
+		events = append(events, evdevEvent{typ: evRel, code: relWheel, value: int32(ticksY)})
+	}
+	if hiX != 0 {
+		events = append(events, evdevEvent{typ: evRel, code: relHWheelHiRes, value: int32(hiX)})
+	}
+	if ticksX != 0 {
+		events = append(events, evdevEvent{typ: evRel, code: relHWheel, value: int32(ticksX)})
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return writeEvents(u.keyboard, append(events, syn)...)
+}
+
+func (u *uinputInjector) typeText(s string, options TypeOptions) error {
+	if options.Mode == TypeUnicode {
+		return errors.New("typing Unicode characters needs an X server")
+	}
+	for _, r := range s {
+		key, ok := usLayout[r]
+		if !ok {
+			return fmt.Errorf("cannot type %q, without an X server only the characters on a US keyboard can be typed", r)
+		}
+
+		var events []evdevEvent
+		if key.shift {
+			events = append(events, keyValue(keyLeftShift, true), syn)
+		}
+		events = append(events,
+			keyValue(key.code, true), syn,
+			keyValue(key.code, false), syn,
+		)
+		if key.shift {
+			events = append(events, keyValue(keyLeftShift, false), syn)
+		}
+
+		u.mu.Lock()
+		err := writeEvents(u.keyboard, events...)
+		u.mu.Unlock()
+		if err != nil {
+			return err
+		}
+
+		time.Sleep(options.Delay)
+	}
+	return nil
+}
+
+// screenSize returns the resolution of the first connected display, according
+// to the kernel's DRM or frame buffer information.
+func screenSize() (width, height int, ok bool) {
+	modes, _ := filepath.Glob("/sys/class/drm/*/modes")
+	for _, path := range modes {
+		status, err := os.ReadFile(filepath.Join(filepath.Dir(path), "status"))
+		if err != nil || strings.TrimSpace(string(status)) != "connected" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		// The first mode is the preferred one, e.g. "1920x1080".
+		line := strings.SplitN(string(data), "\n", 2)[0]
+		if width, height, ok := parseSize(line, "x"); ok {
+			return width, height, true
+		}
+	}
+
+	// The frame buffer size is given as e.g. "1920,1080".
+	data, err := os.ReadFile("/sys/class/graphics/fb0/virtual_size")
+	if err == nil {
+		return parseSize(strings.TrimSpace(string(data)), ",")
+	}
+	return 0, 0, false
+}
+
+func parseSize(s, sep string) (width, height int, ok bool) {
+	parts := strings.SplitN(s, sep, 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	width, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	// Interlaced modes end in "i".
+	height, err = strconv.Atoi(strings.TrimRight(parts[1], "i"))
+	if err != nil {
+		return 0, 0, false
+	}
+	return width, height, width > 0 && height > 0
+}
@@ -0,0 +1,124 @@
+package auto
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUinputEventsReachEvdev(t *testing.T) {
+	f, err := os.OpenFile("/dev/uinput", os.O_WRONLY, 0)
+	if err != nil {
+		t.Skipf("/dev/uinput is not accessible: %v", err)
+	}
+	f.Close()
+
+	u, err := newUinputInjector()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer u.keyboard.Close()
+	if u.pointer != nil {
+		defer u.pointer.Close()
+	}
+
+	dev := openEvdevNode(t, "auto virtual keyboard and mouse")
+	defer dev.Close()
+
+	err = u.inject(
+		keyDown(KeyA),
+		keyUp(KeyA),
+		buttonDown(LeftMouseButton),
+		buttonUp(LeftMouseButton),
+		InputEvent{Type: InputMoveRaw, X: 3, Y: -2},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []evdevEvent{
+		{typ: evKey, code: 30, value: 1}, // KEY_A
+		syn,
+		{typ: evKey, code: 30, value: 0},
+		syn,
+		{typ: evKey, code: btnLeft, value: 1},
+		syn,
+		{typ: evKey, code: btnLeft, value: 0},
+		syn,
+		{typ: evRel, code: relX, value: 3},
+		{typ: evRel, code: relY, value: -2},
+		syn,
+	}
+	if got := readEvdevEvents(t, dev, len(want)); !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %v\nwant %v", got, want)
+	}
+}
+
+// openEvdevNode opens the /dev/input/event* node of the input device with the
+// given name. It waits a while for udev to create the node.
+func openEvdevNode(t *testing.T, name string) *os.File {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		names, _ := filepath.Glob("/sys/class/input/event*/device/name")
+		for _, path := range names {
+			data, err := os.ReadFile(path)
+			if err != nil || strings.TrimSpace(string(data)) != name {
+				continue
+			}
+			node := "/dev/input/" + filepath.Base(filepath.Dir(filepath.Dir(path)))
+			f, err := os.Open(node)
+			if os.IsPermission(err) {
+				t.Skipf("cannot read %s: %v", node, err)
+			}
+			if err == nil {
+				return f
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("no evdev node for %q", name)
+	return nil
+}
+
+// readEvdevEvents reads n events from the evdev node, skipping EV_MSC events
+// which the kernel adds for some keys.
+func readEvdevEvents(t *testing.T, dev *os.File, n int) []evdevEvent {
+	t.Helper()
+	const evMsc = 0x04
+	// The time is a struct timeval which consists of two longs.
+	timeSize := 2 * strconv.IntSize / 8
+
+	result := make(chan []evdevEvent, 1)
+	go func() {
+		var events []evdevEvent
+		buf := make([]byte, timeSize+8)
+		for len(events) < n {
+			if _, err := dev.Read(buf); err != nil {
+				break
+			}
+			b := buf[timeSize:]
+			e := evdevEvent{
+				typ:   nativeEndian.Uint16(b[0:]),
+				code:  nativeEndian.Uint16(b[2:]),
+				value: int32(nativeEndian.Uint32(b[4:])),
+			}
+			if e.typ != evMsc {
+				events = append(events, e)
+			}
+		}
+		result <- events
+	}()
+
+	select {
+	case events := <-result:
+		return events
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timeout reading %d events from the evdev node", n)
+		return nil
+	}
+}
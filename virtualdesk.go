@@ -0,0 +1,39 @@
+package auto
+
+// virtualDeskCoordinates converts the screen position x,y to the normalized
+// coordinates of absolute mouse input on Windows. With the virtual desk flag,
+// Windows maps the range 0 to 65535 onto the virtual desk, which is the
+// bounding rectangle of all monitors. Its origin is negative if a monitor is
+// left of or above the primary monitor.
+//
+// Positions outside the virtual desk are clamped to its border.
+func virtualDeskCoordinates(x, y int, desk Rectangle) (nx, ny int32) {
+	return normalizeAxis(x, desk.X, desk.Width), normalizeAxis(y, desk.Y, desk.Height)
+}
+
+// normalizeAxis maps v in [origin, origin+size) to [0, 65535], the first pixel
+// to 0 and the last one to 65535. Windows goes back to pixels by scaling with
+// size/65536 and truncating, so we round up to the start of the pixel, which
+// makes sure we hit the right one.
+func normalizeAxis(v, origin, size int) int32 {
+	offset := int64(v - origin)
+	if size <= 0 || offset <= 0 {
+		return 0
+	}
+	if offset >= int64(size)-1 {
+		return 65535
+	}
+	// We compute in 64 bits because the intermediate values overflow 32 bits
+	// for large screens.
+	return int32((offset*65536 + int64(size) - 1) / int64(size))
+}
+
+func clamp(x, min, max int) int {
+	if x < min {
+		return min
+	}
+	if x > max {
+		return max
+	}
+	return x
+}
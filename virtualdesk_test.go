@@ -0,0 +1,70 @@
+package auto
+
+import "testing"
+
+func TestNormalizeAxis(t *testing.T) {
+	tests := []struct {
+		name            string
+		v, origin, size int
+		want            int32
+	}{
+		{"first pixel", 0, 0, 1920, 0},
+		{"second pixel", 1, 0, 1920, 35},
+		{"last pixel", 1919, 0, 1920, 65535},
+		{"negative origin, first pixel", -1280, -1280, 3200, 0},
+		{"negative origin, primary monitor", 0, -1280, 3200, 26215},
+		{"negative origin, last pixel", 1919, -1280, 3200, 65535},
+		{"left of the desk", -1281, -1280, 3200, 0},
+		{"far left of the desk", -100000, -1280, 3200, 0},
+		{"right of the desk", 1920, -1280, 3200, 65535},
+		{"far right of the desk", 100000, -1280, 3200, 65535},
+		{"size 1", 5, 5, 1, 0},
+		{"size 1, outside", 6, 5, 1, 65535},
+		{"size 0", 5, 5, 0, 0},
+		{"huge desk", 99999, 0, 100000, 65535},
+	}
+	for _, tt := range tests {
+		got := normalizeAxis(tt.v, tt.origin, tt.size)
+		if got != tt.want {
+			t.Errorf("%s: normalizeAxis(%d, %d, %d) = %d, want %d",
+				tt.name, tt.v, tt.origin, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeAxisHitsEveryPixel(t *testing.T) {
+	// Windows maps normalized coordinates back to pixels by scaling with
+	// size/65536 and truncating.
+	for _, size := range []int{1, 2, 3, 640, 1080, 1920, 3840, 7680, 65536} {
+		for _, origin := range []int{0, -size / 2} {
+			for v := origin; v < origin+size; v++ {
+				n := normalizeAxis(v, origin, size)
+				if pixel := origin + int(int64(n)*int64(size)/65536); pixel != v {
+					t.Fatalf("size %d, origin %d: pixel %d maps to %d, which is pixel %d",
+						size, origin, v, n, pixel)
+				}
+			}
+		}
+	}
+}
+
+func TestVirtualDeskCoordinates(t *testing.T) {
+	// A monitor left of and above the primary monitor gives the virtual desk a
+	// negative origin.
+	desk := Rectangle{X: -1920, Y: -200, Width: 3840, Height: 1280}
+	tests := []struct {
+		x, y   int
+		nx, ny int32
+	}{
+		{-1920, -200, 0, 0},
+		{1919, 1079, 65535, 65535},
+		{0, 0, 32768, 10240},
+		{-5000, 5000, 0, 65535},
+	}
+	for _, tt := range tests {
+		nx, ny := virtualDeskCoordinates(tt.x, tt.y, desk)
+		if nx != tt.nx || ny != tt.ny {
+			t.Errorf("%d,%d: got %d,%d, want %d,%d", tt.x, tt.y, nx, ny, tt.nx, tt.ny)
+		}
+	}
+}
@@ -0,0 +1,832 @@
+package auto
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// This file implements the small subset of the X11 wire protocol that this
+// package needs. We talk to the X server directly over its socket so that no
+// C libraries (Xlib, libXtst, ...) are necessary at compile or run time.
+
+var le = binary.LittleEndian
+
+// xConn is a connection to an X server. Requests can be sent from multiple
+// goroutines. A background goroutine reads replies, errors and events from the
+// server.
+type xConn struct {
+	conn net.Conn
+
+	writeMu sync.Mutex
+	seq     uint16
+
+	replyMu sync.Mutex
+	replies map[uint16]chan xReply
+	readErr error
+
+	// events receives all events that the server sends to this connection. It
+	// is nil if the connection was opened without interest in events, in which
+	// case they are dropped.
+	events chan []byte
+	// pending holds events that were read but not yet put in the events
+	// channel. The read loop never blocks on a slow event reader, this way
+	// replies are still delivered while events pile up.
+	eventMu     sync.Mutex
+	pending     [][]byte
+	eventsDone  bool
+	eventSignal chan struct{}
+	closeOnce   sync.Once
+	closed      chan struct{}
+
+	idMu   sync.Mutex
+	idBase uint32
+	idMask uint32
+	nextID uint32
+
+	// maxRequestLength is the maximum request size in bytes.
+	maxRequestLength int
+	minKeycode       byte
+	maxKeycode       byte
+	formats          []xFormat
+	screen           xScreen
+
+	extMu      sync.Mutex
+	extensions map[string]xExtension
+	atomMu     sync.Mutex
+	atoms      map[string]uint32
+	atomNames  map[uint32]string
+}
+
+type xFormat struct {
+	depth        byte
+	bitsPerPixel byte
+	scanlinePad  byte
+}
+
+type xScreen struct {
+	number int
+	root   uint32
+	width  int
+	height int
+	depth  byte
+	visual uint32
+}
+
+type xExtension struct {
+	present    bool
+	opcode     byte
+	firstEvent byte
+	firstError byte
+}
+
+type xReply struct {
+	data []byte
+	err  error
+}
+
+// xError is an error that the X server sent in response to a request.
+type xError struct {
+	code  byte
+	major byte
+	minor uint16
+	value uint32
+}
+
+func (e xError) Error() string {
+	names := []string{
+		1: "BadRequest", 2: "BadValue", 3: "BadWindow", 4: "BadPixmap",
+		5: "BadAtom", 6: "BadCursor", 7: "BadFont", 8: "BadMatch",
+		9: "BadDrawable", 10: "BadAccess", 11: "BadAlloc", 12: "BadColor",
+		13: "BadGC", 14: "BadIDChoice", 15: "BadName", 16: "BadLength",
+		17: "BadImplementation",
+	}
+	name := "error " + strconv.Itoa(int(e.code))
+	if int(e.code) < len(names) {
+		name = names[e.code]
+	}
+	return fmt.Sprintf(
+		"X11 %s in request %d.%d (value %d)", name, e.major, e.minor, e.value,
+	)
+}
+
+var errNoDisplay = errors.New("DISPLAY is not set, no X server to connect to")
+
+// openDisplay connects to the X server given in the DISPLAY environment
+// variable. If wantEvents is true, all events that the server sends are put in
+// the connection's events channel, otherwise they are discarded.
+func openDisplay(wantEvents bool) (*xConn, error) {
+	display := os.Getenv("DISPLAY")
+	if display == "" {
+		return nil, errNoDisplay
+	}
+
+	host, number, screen, err := parseDisplay(display)
+	if err != nil {
+		return nil, err
+	}
+
+	var conn net.Conn
+	if host == "" || host == "unix" || strings.HasPrefix(host, "/") {
+		path := "/tmp/.X11-unix/X" + number
+		if strings.HasPrefix(host, "/") {
+			path = host
+		}
+		conn, err = net.Dial("unix", path)
+		if err != nil {
+			// Some systems only provide the abstract socket.
+			conn, err = net.Dial("unix", "@"+path)
+		}
+	} else {
+		port, _ := strconv.Atoi(number)
+		conn, err = net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(6000+port)))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to X server %q: %w", display, err)
+	}
+
+	c := &xConn{
+		conn:       conn,
+		replies:    make(map[uint16]chan xReply),
+		extensions: make(map[string]xExtension),
+		atoms:      make(map[string]uint32),
+		atomNames:  make(map[uint32]string),
+		closed:     make(chan struct{}),
+	}
+	if wantEvents {
+		c.events = make(chan []byte, 256)
+		c.eventSignal = make(chan struct{}, 1)
+	}
+
+	authName, authData := readXAuthority(host, number)
+	if err := c.handshake(authName, authData, screen); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	go c.readLoop()
+	if wantEvents {
+		go c.pumpEvents()
+	}
+
+	return c, nil
+}
+
+// parseDisplay splits a DISPLAY string like "host:0.1" into its parts.
+func parseDisplay(display string) (host, number string, screen int, err error) {
+	colon := strings.LastIndex(display, ":")
+	if colon == -1 {
+		return "", "", 0, fmt.Errorf("invalid DISPLAY %q", display)
+	}
+	host = display[:colon]
+	number = display[colon+1:]
+	if dot := strings.Index(number, "."); dot != -1 {
+		screen, err = strconv.Atoi(number[dot+1:])
+		if err != nil {
+			return "", "", 0, fmt.Errorf("invalid screen in DISPLAY %q", display)
+		}
+		number = number[:dot]
+	}
+	if _, err := strconv.Atoi(number); err != nil {
+		return "", "", 0, fmt.Errorf("invalid display number in DISPLAY %q", display)
+	}
+	return host, number, screen, nil
+}
+
+// readXAuthority looks up the authorization cookie for the given display in
+// the Xauthority file. If there is none, empty values are returned and we try
+// to connect without authorization.
+func readXAuthority(host, number string) (name string, data []byte) {
+	path := os.Getenv("XAUTHORITY")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", nil
+		}
+		path = filepath.Join(home, ".Xauthority")
+	}
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil
+	}
+
+	if host == "" || host == "unix" || strings.HasPrefix(host, "/") {
+		host, _ = os.Hostname()
+	}
+
+	const (
+		familyLocal = 256
+		familyWild  = 65535
+	)
+
+	readString := func() ([]byte, bool) {
+		if len(file) < 2 {
+			return nil, false
+		}
+		n := int(binary.BigEndian.Uint16(file))
+		if len(file) < 2+n {
+			return nil, false
+		}
+		s := file[2 : 2+n]
+		file = file[2+n:]
+		return s, true
+	}
+
+	for len(file) >= 2 {
+		family := binary.BigEndian.Uint16(file)
+		file = file[2:]
+		address, ok1 := readString()
+		num, ok2 := readString()
+		authName, ok3 := readString()
+		authData, ok4 := readString()
+		if !(ok1 && ok2 && ok3 && ok4) {
+			break
+		}
+		addressMatches := family == familyWild ||
+			family == familyLocal && string(address) == host
+		numberMatches := len(num) == 0 || string(num) == number
+		if addressMatches && numberMatches {
+			return string(authName), authData
+		}
+	}
+	return "", nil
+}
+
+func (c *xConn) handshake(authName string, authData []byte, screen int) error {
+	req := []byte{'l', 0, 11, 0, 0, 0}
+	req = xRequest(req).u16(uint16(len(authName))).u16(uint16(len(authData)))
+	req = append(req, 0, 0)
+	req = append(req, authName...)
+	req = append(req, make([]byte, pad(len(authName)))...)
+	req = append(req, authData...)
+	req = append(req, make([]byte, pad(len(authData)))...)
+	if _, err := c.conn.Write(req); err != nil {
+		return err
+	}
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return fmt.Errorf("X server setup failed: %w", err)
+	}
+	body := make([]byte, 4*int(le.Uint16(header[6:])))
+	if _, err := io.ReadFull(c.conn, body); err != nil {
+		return fmt.Errorf("X server setup failed: %w", err)
+	}
+	if header[0] != 1 {
+		reason := string(body)
+		if header[0] == 0 {
+			if n := int(header[1]); n < len(body) {
+				reason = string(body[:n])
+			}
+		}
+		return fmt.Errorf("X server refused connection: %s", reason)
+	}
+
+	c.idBase = le.Uint32(body[4:])
+	c.idMask = le.Uint32(body[8:])
+	vendorLength := int(le.Uint16(body[16:]))
+	c.maxRequestLength = 4 * int(le.Uint16(body[18:]))
+	screenCount := int(body[20])
+	formatCount := int(body[21])
+	c.minKeycode = body[26]
+	c.maxKeycode = body[27]
+
+	offset := 32 + vendorLength + pad(vendorLength)
+	for i := 0; i < formatCount; i++ {
+		c.formats = append(c.formats, xFormat{
+			depth:        body[offset],
+			bitsPerPixel: body[offset+1],
+			scanlinePad:  body[offset+2],
+		})
+		offset += 8
+	}
+
+	if screen >= screenCount {
+		return fmt.Errorf("X server has no screen %d", screen)
+	}
+	for i := 0; ; i++ {
+		s := body[offset:]
+		if i == screen {
+			c.screen = xScreen{
+				number: screen,
+				root:   le.Uint32(s[0:]),
+				width:  int(le.Uint16(s[20:])),
+				height: int(le.Uint16(s[22:])),
+				visual: le.Uint32(s[32:]),
+				depth:  s[38],
+			}
+			break
+		}
+		// Skip this screen's list of depths and their visuals.
+		depthCount := int(s[39])
+		offset += 40
+		for d := 0; d < depthCount; d++ {
+			visualCount := int(le.Uint16(body[offset+2:]))
+			offset += 8 + 24*visualCount
+		}
+	}
+
+	return nil
+}
+
+func (c *xConn) readLoop() {
+	for {
+		buf := make([]byte, 32)
+		if _, err := io.ReadFull(c.conn, buf); err != nil {
+			c.fail(err)
+			return
+		}
+
+		// Replies and generic events can be longer than 32 bytes.
+		if buf[0] == 1 || buf[0]&0x7F == xGenericEvent {
+			extra := 4 * int(le.Uint32(buf[4:]))
+			if extra > 0 {
+				buf = append(buf, make([]byte, extra)...)
+				if _, err := io.ReadFull(c.conn, buf[32:]); err != nil {
+					c.fail(err)
+					return
+				}
+			}
+		}
+
+		switch buf[0] {
+		case 0:
+			c.deliver(le.Uint16(buf[2:]), xReply{err: xError{
+				code:  buf[1],
+				value: le.Uint32(buf[4:]),
+				minor: le.Uint16(buf[8:]),
+				major: buf[10],
+			}})
+		case 1:
+			c.deliver(le.Uint16(buf[2:]), xReply{data: buf})
+		default:
+			if c.events != nil {
+				c.queueEvent(buf)
+			}
+		}
+	}
+}
+
+func (c *xConn) deliver(seq uint16, r xReply) {
+	c.replyMu.Lock()
+	reply, ok := c.replies[seq]
+	delete(c.replies, seq)
+	c.replyMu.Unlock()
+	if ok {
+		reply <- r
+	}
+}
+
+func (c *xConn) fail(err error) {
+	c.replyMu.Lock()
+	c.readErr = err
+	for seq, reply := range c.replies {
+		reply <- xReply{err: err}
+		delete(c.replies, seq)
+	}
+	c.replyMu.Unlock()
+	if c.events != nil {
+		c.eventMu.Lock()
+		c.eventsDone = true
+		c.eventMu.Unlock()
+		c.signalEvents()
+	}
+}
+
+func (c *xConn) queueEvent(e []byte) {
+	c.eventMu.Lock()
+	c.pending = append(c.pending, e)
+	c.eventMu.Unlock()
+	c.signalEvents()
+}
+
+func (c *xConn) signalEvents() {
+	select {
+	case c.eventSignal <- struct{}{}:
+	default:
+	}
+}
+
+// pumpEvents moves queued events to the events channel. The channel is closed
+// once the connection fails and all queued events are taken, or when the
+// connection is closed.
+func (c *xConn) pumpEvents() {
+	defer close(c.events)
+	for {
+		c.eventMu.Lock()
+		if len(c.pending) == 0 {
+			done := c.eventsDone
+			c.eventMu.Unlock()
+			if done {
+				return
+			}
+			select {
+			case <-c.eventSignal:
+			case <-c.closed:
+				return
+			}
+			continue
+		}
+		e := c.pending[0]
+		c.pending[0] = nil
+		c.pending = c.pending[1:]
+		c.eventMu.Unlock()
+
+		select {
+		case c.events <- e:
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+func (c *xConn) broken() error {
+	c.replyMu.Lock()
+	defer c.replyMu.Unlock()
+	return c.readErr
+}
+
+func (c *xConn) close() {
+	c.closeOnce.Do(func() { close(c.closed) })
+	c.conn.Close()
+}
+
+// xCookie identifies a sent request. Its channel receives the reply or error
+// for the request.
+type xCookie struct {
+	seq   uint16
+	reply chan xReply
+}
+
+// forget stops waiting for a reply to the request.
+func (c *xConn) forget(cookie xCookie) {
+	c.replyMu.Lock()
+	delete(c.replies, cookie.seq)
+	c.replyMu.Unlock()
+}
+
+// send writes the given request to the server. If wantReply is true, the
+// returned cookie's channel will receive the reply or error for this request.
+func (c *xConn) send(r xRequest, wantReply bool) (xCookie, error) {
+	return c.sendWithFile(r, wantReply, nil)
+}
+
+// sendWithFile is like send but also passes the given file descriptor to the
+// server if file is not nil. This only works for local connections.
+func (c *xConn) sendWithFile(r xRequest, wantReply bool, file *os.File) (xCookie, error) {
+	r = r.finish()
+	if len(r) > c.maxRequestLength {
+		return xCookie{}, fmt.Errorf("X11 request of %d bytes is too long", len(r))
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.seq++
+	cookie := xCookie{seq: c.seq}
+	if wantReply {
+		cookie.reply = make(chan xReply, 1)
+		c.replyMu.Lock()
+		if c.readErr != nil {
+			c.replyMu.Unlock()
+			return xCookie{}, c.readErr
+		}
+		c.replies[c.seq] = cookie.reply
+		c.replyMu.Unlock()
+	}
+
+	var err error
+	if file == nil {
+		_, err = c.conn.Write(r)
+	} else if unix, ok := c.conn.(*net.UnixConn); ok {
+		_, _, err = unix.WriteMsgUnix(r, syscall.UnixRights(int(file.Fd())), nil)
+	} else {
+		err = errors.New("file descriptors can only be passed to a local X server")
+	}
+	if err != nil {
+		if wantReply {
+			c.forget(cookie)
+		}
+		return xCookie{}, err
+	}
+	return cookie, nil
+}
+
+// roundTrip sends a request that has a reply and waits for that reply.
+func (c *xConn) roundTrip(r xRequest) ([]byte, error) {
+	cookie, err := c.send(r, true)
+	if err != nil {
+		return nil, err
+	}
+	result := <-cookie.reply
+	return result.data, result.err
+}
+
+// post sends a request without a reply. Errors that the server reports for
+// this request are ignored.
+func (c *xConn) post(r xRequest) error {
+	_, err := c.send(r, false)
+	return err
+}
+
+// exec sends the given requests, which have no reply, and waits until the
+// server has processed them. The first error that the server reports for any
+// of them is returned.
+func (c *xConn) exec(requests ...xRequest) error {
+	cookies := make([]xCookie, 0, len(requests))
+	defer func() {
+		// No replies will ever arrive for these requests, only errors.
+		for _, cookie := range cookies {
+			c.forget(cookie)
+		}
+	}()
+
+	for _, r := range requests {
+		cookie, err := c.send(r, true)
+		if err != nil {
+			return err
+		}
+		cookies = append(cookies, cookie)
+	}
+	if err := c.sync(); err != nil {
+		return err
+	}
+	for _, cookie := range cookies {
+		select {
+		case result := <-cookie.reply:
+			return result.err
+		default:
+		}
+	}
+	return nil
+}
+
+// sync waits until the server has processed all requests sent so far.
+func (c *xConn) sync() error {
+	const getInputFocus = 43
+	_, err := c.roundTrip(newXRequest(getInputFocus, 0))
+	return err
+}
+
+// newID allocates a new resource ID, e.g. for a window.
+func (c *xConn) newID() (uint32, error) {
+	c.idMu.Lock()
+	defer c.idMu.Unlock()
+	c.nextID++
+	id := c.nextID << bits.TrailingZeros32(c.idMask)
+	if id&^c.idMask != 0 {
+		return 0, errors.New("X11 resource IDs exhausted")
+	}
+	return c.idBase | id, nil
+}
+
+// extension returns the major opcode and first event and error codes for the
+// X extension of the given name. If the server does not support it, an error
+// is returned.
+func (c *xConn) extension(name string) (xExtension, error) {
+	c.extMu.Lock()
+	ext, ok := c.extensions[name]
+	c.extMu.Unlock()
+	if !ok {
+		const queryExtension = 98
+		reply, err := c.roundTrip(
+			newXRequest(queryExtension, 0).
+				u16(uint16(len(name))).pad(2).
+				str(name),
+		)
+		if err != nil {
+			return xExtension{}, err
+		}
+		ext = xExtension{
+			present:    reply[8] != 0,
+			opcode:     reply[9],
+			firstEvent: reply[10],
+			firstError: reply[11],
+		}
+		c.extMu.Lock()
+		c.extensions[name] = ext
+		c.extMu.Unlock()
+	}
+	if !ext.present {
+		return ext, fmt.Errorf("the X server does not support the %s extension", name)
+	}
+	return ext, nil
+}
+
+// atom returns the atom for the given name, creating it if necessary.
+func (c *xConn) atom(name string) (uint32, error) {
+	c.atomMu.Lock()
+	a, ok := c.atoms[name]
+	c.atomMu.Unlock()
+	if ok {
+		return a, nil
+	}
+
+	const internAtom = 16
+	reply, err := c.roundTrip(
+		newXRequest(internAtom, 0).
+			u16(uint16(len(name))).pad(2).
+			str(name),
+	)
+	if err != nil {
+		return 0, err
+	}
+	a = le.Uint32(reply[8:])
+
+	c.atomMu.Lock()
+	c.atoms[name] = a
+	c.atomNames[a] = name
+	c.atomMu.Unlock()
+	return a, nil
+}
+
+// atomName returns the name of the given atom.
+func (c *xConn) atomName(a uint32) (string, error) {
+	c.atomMu.Lock()
+	name, ok := c.atomNames[a]
+	c.atomMu.Unlock()
+	if ok {
+		return name, nil
+	}
+
+	const getAtomName = 17
+	reply, err := c.roundTrip(newXRequest(getAtomName, 0).u32(a))
+	if err != nil {
+		return "", err
+	}
+	n := int(le.Uint16(reply[8:]))
+	name = string(reply[32 : 32+n])
+
+	c.atomMu.Lock()
+	c.atoms[name] = a
+	c.atomNames[a] = name
+	c.atomMu.Unlock()
+	return name, nil
+}
+
+// xProperty is the value of a window property.
+type xProperty struct {
+	typ        uint32
+	format     byte
+	data       []byte
+	bytesAfter uint32
+}
+
+// uint32s interprets the property as a list of 32 bit values, e.g. CARDINAL,
+// WINDOW or ATOM lists.
+func (p xProperty) uint32s() []uint32 {
+	if p.format != 32 {
+		return nil
+	}
+	values := make([]uint32, len(p.data)/4)
+	for i := range values {
+		values[i] = le.Uint32(p.data[4*i:])
+	}
+	return values
+}
+
+// getProperty reads the given window property. typ can be 0 (AnyPropertyType).
+// If the property does not exist, the returned property has type 0 and no
+// data.
+func (c *xConn) getProperty(window, property, typ uint32, delete bool) (xProperty, error) {
+	const getProperty = 20
+	var result xProperty
+	var offset uint32
+	for {
+		reply, err := c.roundTrip(
+			newXRequest(getProperty, boolByte(delete)).
+				u32(window).
+				u32(property).
+				u32(typ).
+				u32(offset).
+				u32(1 << 20), // In units of 4 bytes.
+		)
+		if err != nil {
+			return xProperty{}, err
+		}
+		result.format = reply[1]
+		result.typ = le.Uint32(reply[8:])
+		result.bytesAfter = le.Uint32(reply[12:])
+		n := int(le.Uint32(reply[16:])) * int(result.format) / 8
+		result.data = append(result.data, reply[32:32+n]...)
+		if result.bytesAfter == 0 || result.typ == 0 || delete {
+			return result, nil
+		}
+		offset = uint32(len(result.data) / 4)
+	}
+}
+
+// xRequest is the binary encoding of a request. Use newXRequest to create one
+// and the methods to append fields in order. The length field is filled in
+// when the request is sent.
+type xRequest []byte
+
+func newXRequest(opcode, data byte) xRequest {
+	return xRequest{opcode, data, 0, 0}
+}
+
+func (r xRequest) u8(v byte) xRequest {
+	return append(r, v)
+}
+
+func (r xRequest) u16(v uint16) xRequest {
+	return append(r, byte(v), byte(v>>8))
+}
+
+func (r xRequest) i16(v int) xRequest {
+	return r.u16(uint16(int16(v)))
+}
+
+func (r xRequest) u32(v uint32) xRequest {
+	return append(r, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func (r xRequest) pad(n int) xRequest {
+	return append(r, make([]byte, n)...)
+}
+
+// str appends the given string and pads it to a multiple of 4 bytes.
+func (r xRequest) str(s string) xRequest {
+	r = append(r, s...)
+	return r.pad(pad(len(s)))
+}
+
+// bytes appends the given data and pads it to a multiple of 4 bytes.
+func (r xRequest) bytes(b []byte) xRequest {
+	r = append(r, b...)
+	return r.pad(pad(len(b)))
+}
+
+func (r xRequest) finish() xRequest {
+	r = r.pad(pad(len(r)))
+	le.PutUint16(r[2:], uint16(len(r)/4))
+	return r
+}
+
+// pad returns the number of bytes needed to pad n to a multiple of 4.
+func pad(n int) int {
+	return (4 - n%4) % 4
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+const xGenericEvent = 35
+
+// These are predefined atoms that need not be interned.
+const (
+	xAtomAtom     = 4
+	xAtomCardinal = 6
+	xAtomString   = 31
+	xAtomWindow   = 33
+	xAtomWMName   = 39
+	xAtomWMClass  = 67
+)
+
+// sendEvent returns a SendEvent request that sends the given 32 byte event to
+// the destination window.
+func (c *xConn) sendEvent(destination, eventMask uint32, event []byte) xRequest {
+	const sendEvent = 25
+	return newXRequest(sendEvent, 0).
+		u32(destination).
+		u32(eventMask).
+		bytes(event)
+}
+
+var x11 struct {
+	mu   sync.Mutex
+	conn *xConn
+}
+
+// display returns the shared connection to the X server. It is opened on first
+// use and re-opened if it broke down.
+func display() (*xConn, error) {
+	x11.mu.Lock()
+	defer x11.mu.Unlock()
+
+	if x11.conn != nil && x11.conn.broken() != nil {
+		x11.conn.close()
+		x11.conn = nil
+	}
+	if x11.conn == nil {
+		c, err := openDisplay(false)
+		if err != nil {
+			return nil, err
+		}
+		x11.conn = c
+	}
+	return x11.conn, nil
+}
@@ -0,0 +1,135 @@
+package auto
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// xSettings reads the integer settings of the XSETTINGS manager. Desktop
+// environments use XSETTINGS to share settings like the double-click time or
+// the drag threshold with all applications. The settings manager owns the
+// selection _XSETTINGS_S<screen> and keeps the settings in a property of its
+// window. If no settings manager runs, the returned map is empty.
+func (c *xConn) xSettings() (map[string]int, error) {
+	selection, err := c.atom("_XSETTINGS_S" + strconv.Itoa(c.screen.number))
+	if err != nil {
+		return nil, err
+	}
+	const getSelectionOwner = 23
+	reply, err := c.roundTrip(newXRequest(getSelectionOwner, 0).u32(selection))
+	if err != nil {
+		return nil, err
+	}
+	owner := le.Uint32(reply[8:])
+	if owner == 0 {
+		return map[string]int{}, nil
+	}
+
+	settingsAtom, err := c.atom("_XSETTINGS_SETTINGS")
+	if err != nil {
+		return nil, err
+	}
+	prop, err := c.getProperty(owner, settingsAtom, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	return parseXSettings(prop.data)
+}
+
+// parseXSettings decodes the _XSETTINGS_SETTINGS property and returns the
+// integer settings. String and color settings are skipped.
+func parseXSettings(data []byte) (map[string]int, error) {
+	errInvalid := errors.New("invalid _XSETTINGS_SETTINGS property")
+	if len(data) < 12 {
+		return nil, errInvalid
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if data[0] == 1 {
+		order = binary.BigEndian
+	}
+	count := int(order.Uint32(data[8:]))
+	data = data[12:]
+
+	const (
+		typeInteger = 0
+		typeString  = 1
+		typeColor   = 2
+	)
+	settings := make(map[string]int)
+	for i := 0; i < count; i++ {
+		if len(data) < 4 {
+			return nil, errInvalid
+		}
+		typ := data[0]
+		nameLen := int(order.Uint16(data[2:]))
+		// After the name comes the serial of the last change, then the value.
+		valueStart := 4 + nameLen + pad(nameLen) + 4
+		if len(data) < valueStart {
+			return nil, errInvalid
+		}
+		name := string(data[4 : 4+nameLen])
+		data = data[valueStart:]
+
+		switch typ {
+		case typeInteger:
+			if len(data) < 4 {
+				return nil, errInvalid
+			}
+			settings[name] = int(int32(order.Uint32(data)))
+			data = data[4:]
+		case typeString:
+			if len(data) < 4 {
+				return nil, errInvalid
+			}
+			n := int(order.Uint32(data))
+			if len(data) < 4+n+pad(n) {
+				return nil, errInvalid
+			}
+			data = data[4+n+pad(n):]
+		case typeColor:
+			if len(data) < 8 {
+				return nil, errInvalid
+			}
+			data = data[8:]
+		default:
+			return nil, errInvalid
+		}
+	}
+	return settings, nil
+}
+
+// xSetting returns the integer XSETTINGS value of the given name, e.g.
+// "Net/DoubleClickTime". If there is no X server, no settings manager or no
+// such setting, it returns the given default.
+func xSetting(name string, defaultValue int) int {
+	c, err := display()
+	if err != nil {
+		return defaultValue
+	}
+	settings, err := c.xSettings()
+	if err != nil {
+		return defaultValue
+	}
+	if value, ok := settings[name]; ok {
+		return value
+	}
+	return defaultValue
+}
+
+// dragThreshold returns the distance in pixels that the mouse has to move with
+// a button held down to start a drag. 8 is the default of GTK.
+func dragThreshold() int {
+	return xSetting("Net/DndDragThreshold", 8)
+}
+
+// doubleClickLimits returns the maximum time between two clicks of a double
+// click and the size of the rectangle, centered on the first click, that the
+// second click has to be in. The defaults are those of GTK, which allows the
+// second click to be DoubleClickDistance pixels away in each direction.
+func doubleClickLimits() (limit time.Duration, width, height int) {
+	ms := xSetting("Net/DoubleClickTime", 400)
+	distance := xSetting("Net/DoubleClickDistance", 5)
+	return time.Duration(ms) * time.Millisecond, 2 * distance, 2 * distance
+}
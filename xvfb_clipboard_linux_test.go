@@ -0,0 +1,60 @@
+package auto
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestX11ClipboardFromOtherOwner(t *testing.T) {
+	testDisplay(t)
+
+	// Another selection owner takes the clipboard, ClipboardText has to ask it
+	// through the X server.
+	var other selectionOwner
+	if err := other.set("CLIPBOARD", "small"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { other.conn.close() })
+	if !eventually(func() bool {
+		_, ok := owner.text("CLIPBOARD")
+		return !ok
+	}) {
+		t.Fatal("our own selection owner still has the clipboard")
+	}
+
+	// The large text does not fit into a single request and goes through the
+	// INCR protocol.
+	large := strings.Repeat("0123456789", 2*other.chunkSize()/10+3)
+	for _, want := range []string{"small", "ünicode", large} {
+		if err := other.set("CLIPBOARD", want); err != nil {
+			t.Fatal(err)
+		}
+		text, err := ClipboardText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if text != want {
+			t.Errorf("want %d bytes %.20q but have %d bytes %.20q", len(want), want, len(text), text)
+		}
+	}
+}
+
+func TestX11PrimarySelection(t *testing.T) {
+	testDisplay(t)
+	t.Cleanup(func() { UsePrimarySelection(false) })
+
+	if err := SetClipboardText("copied"); err != nil {
+		t.Fatal(err)
+	}
+	UsePrimarySelection(true)
+	if err := SetClipboardText("selected"); err != nil {
+		t.Fatal(err)
+	}
+	if text, err := ClipboardText(); err != nil || text != "selected" {
+		t.Errorf("want the primary selection %q but have %q (%v)", "selected", text, err)
+	}
+	UsePrimarySelection(false)
+	if text, err := ClipboardText(); err != nil || text != "copied" {
+		t.Errorf("want the clipboard %q but have %q (%v)", "copied", text, err)
+	}
+}
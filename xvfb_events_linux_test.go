@@ -0,0 +1,65 @@
+package auto
+
+import (
+	"testing"
+	"time"
+)
+
+func TestX11InputEvents(t *testing.T) {
+	testDisplay(t)
+	var d linuxDriver
+	// Start somewhere else so that moving the mouse later creates an event.
+	if err := MoveMouseTo(0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := make(chan KeyboardEvent, 100)
+	mouse := make(chan MouseEvent, 100)
+	d.SetOnKeyboardEvent(func(e *KeyboardEvent) { keys <- *e })
+	d.SetOnMouseEvent(func(e *MouseEvent) { mouse <- *e })
+	t.Cleanup(func() {
+		d.SetOnKeyboardEvent(nil)
+		d.SetOnMouseEvent(nil)
+	})
+	if err := SetCancelableKeys(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := TypeKey(KeyA); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []KeyboardEvent{
+		{Key: KeyA, Down: true, Injected: true},
+		{Key: KeyA, Down: false, Injected: true},
+	} {
+		select {
+		case e := <-keys:
+			if e != want {
+				t.Errorf("want keyboard event %+v but have %+v", want, e)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("no keyboard event %+v", want)
+		}
+	}
+
+	if err := MoveMouseTo(100, 50); err != nil {
+		t.Fatal(err)
+	}
+	if err := ClickLeftMouse(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []MouseEvent{
+		{Type: MouseMove, X: 100, Y: 50, Injected: true},
+		{Type: LeftMouseDown, X: 100, Y: 50, Injected: true},
+		{Type: LeftMouseUp, X: 100, Y: 50, Injected: true},
+	} {
+		select {
+		case e := <-mouse:
+			if e != want {
+				t.Errorf("want mouse event %+v but have %+v", want, e)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("no mouse event %+v", want)
+		}
+	}
+}
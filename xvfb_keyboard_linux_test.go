@@ -0,0 +1,199 @@
+package auto
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestX11PressKey(t *testing.T) {
+	testDisplay(t)
+
+	if err := PressKey(KeyA); err != nil {
+		t.Fatal(err)
+	}
+	if down, err := IsKeyDown(KeyA); err != nil || !down {
+		t.Errorf("KeyA is not down after pressing it (%v)", err)
+	}
+	if err := ReleaseKey(KeyA); err != nil {
+		t.Fatal(err)
+	}
+	if down, err := IsKeyDown(KeyA); err != nil || down {
+		t.Errorf("KeyA is still down after releasing it (%v)", err)
+	}
+}
+
+func TestX11KeyInputReachesWindow(t *testing.T) {
+	c := testDisplay(t)
+	window := createFocusedTestWindow(t, c)
+
+	if err := TypeKey(KeyA); err != nil {
+		t.Fatal(err)
+	}
+	e := waitForWindowEvent(t, c, xKeyPressEvent, window)
+	mapping, err := c.keyboardMapping()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sym := mapping.keySym(e[1]); sym != 'a' {
+		t.Errorf("want key symbol 'a' but got %#x", sym)
+	}
+}
+
+func TestX11TypeRestoresKeyboardMapping(t *testing.T) {
+	c := testDisplay(t)
+	window := createFocusedTestWindow(t, c)
+	before, err := c.keyboardMapping()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// None of these are on the US layout, they are typed with spare key
+	// codes. The ä is repeated to reuse its key code.
+	text := "ä€😀ä"
+	if err := Type(text); err != nil {
+		t.Fatal(err)
+	}
+	for range []rune(text) {
+		waitForWindowEvent(t, c, xKeyPressEvent, window)
+	}
+
+	after, err := c.keyboardMapping()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(before, after) {
+		t.Error("the keyboard mapping was not restored after typing")
+	}
+	if keys, _ := Held(); len(keys) != 0 {
+		t.Errorf("keys %v are still held after typing", keys)
+	}
+}
+
+func TestX11TypeWithDelay(t *testing.T) {
+	c := testDisplay(t)
+	window := createFocusedTestWindow(t, c)
+
+	const delay = 100 * time.Millisecond
+	start := time.Now()
+	if err := TypeWithDelay("abc", delay); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 3*delay {
+		t.Errorf("typing 3 characters with a delay of %v took only %v", delay, d)
+	}
+	for i := 0; i < 3; i++ {
+		waitForWindowEvent(t, c, xKeyPressEvent, window)
+	}
+}
+
+func TestX11PressKeyNotOnLayout(t *testing.T) {
+	c := testDisplay(t)
+	before, err := c.keyboardMapping()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, ok := keyNotOnLayout(before)
+	if !ok {
+		t.Skip("all keys are on the keyboard layout")
+	}
+
+	if err := PressKey(key); err != nil {
+		t.Fatal(err)
+	}
+	if down, err := IsKeyDown(key); err != nil || !down {
+		t.Errorf("%s is not down after pressing it (%v)", KeyName(key), err)
+	}
+	if err := ReleaseKey(key); err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := c.keyboardMapping()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(before, after) {
+		t.Errorf("the spare key code for %s was not unmapped", KeyName(key))
+	}
+}
+
+// createFocusedTestWindow creates a window that receives key presses and gives
+// it the keyboard focus.
+func createFocusedTestWindow(t *testing.T, c *xConn) uint32 {
+	t.Helper()
+	window := createTestWindow(t, c, testWindow{
+		Rectangle:        Rectangle{X: 0, Y: 0, Width: 200, Height: 200},
+		overrideRedirect: true,
+		eventMask:        xKeyPressMask,
+	})
+	const (
+		setInputFocus       = 42
+		revertToPointerRoot = 1
+	)
+	err := c.exec(newXRequest(setInputFocus, revertToPointerRoot).u32(window).u32(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return window
+}
+
+// keyNotOnLayout returns a key with an X11 key symbol that is not mapped to any
+// key code.
+func keyNotOnLayout(m xKeyboardMapping) (uint16, bool) {
+	var keys []int
+	for key := range keySyms {
+		keys = append(keys, int(key))
+	}
+	sort.Ints(keys)
+	for _, key := range keys {
+		if _, _, ok := m.find(keySyms[uint16(key)]); !ok {
+			return uint16(key), true
+		}
+	}
+	return 0, false
+}
+
+func TestX11SetLockKey(t *testing.T) {
+	testDisplay(t)
+	t.Cleanup(func() { SetLockKey(KeyCapsLock, false) })
+
+	for _, on := range []bool{true, true, false, false} {
+		if err := SetLockKey(KeyCapsLock, on); err != nil {
+			t.Fatal(err)
+		}
+		if toggled, err := IsKeyToggled(KeyCapsLock); err != nil || toggled != on {
+			t.Errorf("want CapsLock toggled %v after setting it but have %v (%v)", on, toggled, err)
+		}
+	}
+}
+
+func TestX11ModifiersDown(t *testing.T) {
+	testDisplay(t)
+
+	if down, err := ModifiersDown(); err != nil || len(down) != 0 {
+		t.Fatalf("want no modifiers down but have %v (%v)", down, err)
+	}
+	if err := PressKey(KeyShift); err != nil {
+		t.Fatal(err)
+	}
+	if down, err := ModifiersDown(); err != nil || !reflect.DeepEqual(down, []uint16{KeyShift}) {
+		t.Errorf("want Shift down but have %v (%v)", down, err)
+	}
+	// Sides are reported as the generic key.
+	if err := PressKey(KeyRightControl); err != nil {
+		t.Fatal(err)
+	}
+	if down, err := ModifiersDown(); err != nil || !reflect.DeepEqual(down, []uint16{KeyShift, KeyControl}) {
+		t.Errorf("want Shift and Control down but have %v (%v)", down, err)
+	}
+	if err := ReleaseKey(KeyRightControl); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReleaseKey(KeyShift); err != nil {
+		t.Fatal(err)
+	}
+	if down, err := ModifiersDown(); err != nil || len(down) != 0 {
+		t.Errorf("want no modifiers down after releasing them but have %v (%v)", down, err)
+	}
+}
@@ -0,0 +1,127 @@
+// The xvfb tests generate real input on the X server given in DISPLAY and
+// are skipped if it is not set. Run them on a virtual X server so they do not
+// type into your desktop:
+//
+//	xvfb-run go test ./...
+//
+// The window tests need a window manager that supports EWMH, they are skipped
+// without one.
+
+package auto
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+const (
+	xKeyPressMask        = 1 << 0
+	xButtonPressMask     = 1 << 2
+	xStructureNotifyMask = 1 << 17
+
+	xKeyPressEvent    = 2
+	xButtonPressEvent = 4
+	xMapNotifyEvent   = 19
+)
+
+// testDisplay returns a new connection to the X server that receives events.
+// The test is skipped if there is no X server. All input that the test leaves
+// held is released and the connection is closed, which destroys its windows,
+// when the test ends.
+func testDisplay(t *testing.T) *xConn {
+	t.Helper()
+	if os.Getenv("DISPLAY") == "" {
+		t.Skip("DISPLAY is not set, run the tests with xvfb-run")
+	}
+	c, err := openDisplay(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		ReleaseAll()
+		c.close()
+	})
+	return c
+}
+
+// testWindow describes a window for createTestWindow.
+type testWindow struct {
+	Rectangle
+	// background is the pixel value that the window is filled with.
+	background uint32
+	// overrideRedirect windows are not managed by the window manager, they
+	// appear exactly where they are created, on top of all other windows.
+	overrideRedirect bool
+	eventMask        uint32
+	title            string
+	class            string
+}
+
+// createTestWindow creates and maps the window and waits until it is mapped.
+func createTestWindow(t *testing.T, c *xConn, w testWindow) uint32 {
+	t.Helper()
+	window, err := c.newID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const (
+		createWindow       = 1
+		mapWindow          = 8
+		inputOutput        = 1
+		cwBackPixel        = 0x2
+		cwOverrideRedirect = 0x200
+		cwEventMask        = 0x800
+	)
+	// Depth 0 and visual 0 mean that we copy them from the parent window.
+	err = c.exec(
+		newXRequest(createWindow, 0).
+			u32(window).
+			u32(c.screen.root).
+			i16(w.X).
+			i16(w.Y).
+			u16(uint16(w.Width)).
+			u16(uint16(w.Height)).
+			u16(0). // Border width.
+			u16(inputOutput).
+			u32(0).
+			u32(cwBackPixel|cwOverrideRedirect|cwEventMask).
+			u32(w.background).
+			u32(uint32(boolByte(w.overrideRedirect))).
+			u32(w.eventMask|xStructureNotifyMask),
+		changeProperty(window, xAtomWMName, xAtomString, 8, []byte(w.title)),
+		changeProperty(window, xAtomWMClass, xAtomString, 8, []byte(w.class+"\x00"+w.class+"\x00")),
+		newXRequest(mapWindow, 0).u32(window),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForWindowEvent(t, c, xMapNotifyEvent, window)
+	return window
+}
+
+// waitForWindowEvent reads events until one of the given type arrives for the
+// window and returns it. Other events are discarded.
+func waitForWindowEvent(t *testing.T, c *xConn, typ byte, window uint32) []byte {
+	t.Helper()
+	// Most events name their window at offset 12, MapNotify at offset 8.
+	offset := 12
+	if typ == xMapNotifyEvent {
+		offset = 8
+	}
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case e, ok := <-c.events:
+			if !ok {
+				t.Fatalf("the connection broke down: %v", c.broken())
+			}
+			if e[0]&0x7F == typ && le.Uint32(e[offset:]) == window {
+				return e
+			}
+		case <-timeout:
+			t.Fatalf("timeout waiting for event %d on window %d", typ, window)
+			return nil
+		}
+	}
+}
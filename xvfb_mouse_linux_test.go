@@ -0,0 +1,88 @@
+package auto
+
+import "testing"
+
+func TestX11MoveMouse(t *testing.T) {
+	testDisplay(t)
+
+	if err := MoveMouseTo(10, 20); err != nil {
+		t.Fatal(err)
+	}
+	if x, y, err := MousePosition(); err != nil || x != 10 || y != 20 {
+		t.Fatalf("want mouse at 10,20 but have %d,%d (%v)", x, y, err)
+	}
+
+	if err := MoveMouseBy(5, -5); err != nil {
+		t.Fatal(err)
+	}
+	if x, y, err := MousePosition(); err != nil || x != 15 || y != 15 {
+		t.Fatalf("want mouse at 15,15 but have %d,%d (%v)", x, y, err)
+	}
+}
+
+func TestX11MouseButtons(t *testing.T) {
+	testDisplay(t)
+
+	buttons := []struct {
+		key            uint16
+		press, release func() error
+	}{
+		{KeyLeftButton, PressLeftMouse, ReleaseLeftMouse},
+		{KeyRightButton, PressRightMouse, ReleaseRightMouse},
+		{KeyMiddleButton, PressMiddleMouse, ReleaseMiddleMouse},
+	}
+	for _, b := range buttons {
+		if err := b.press(); err != nil {
+			t.Fatal(err)
+		}
+		if down, err := IsKeyDown(b.key); err != nil || !down {
+			t.Errorf("%s is not down after pressing it (%v)", KeyName(b.key), err)
+		}
+		if err := b.release(); err != nil {
+			t.Fatal(err)
+		}
+		if down, err := IsKeyDown(b.key); err != nil || down {
+			t.Errorf("%s is still down after releasing it (%v)", KeyName(b.key), err)
+		}
+	}
+}
+
+func TestX11MouseInputReachesWindow(t *testing.T) {
+	c := testDisplay(t)
+	window := createTestWindow(t, c, testWindow{
+		Rectangle:        Rectangle{X: 0, Y: 0, Width: 200, Height: 200},
+		overrideRedirect: true,
+		eventMask:        xButtonPressMask,
+	})
+
+	if err := ClickLeftMouseAt(100, 100); err != nil {
+		t.Fatal(err)
+	}
+	e := waitForWindowEvent(t, c, xButtonPressEvent, window)
+	if button := e[1]; button != xButtonLeft {
+		t.Errorf("want a click with button %d but got button %d", xButtonLeft, button)
+	}
+	// The event has the pointer position relative to the window at offset 24.
+	if x, y := int16(le.Uint16(e[24:])), int16(le.Uint16(e[26:])); x != 100 || y != 100 {
+		t.Errorf("want the click at 100,100 but it is at %d,%d", x, y)
+	}
+
+	wheel := []struct {
+		dx, dy float64
+		button byte
+	}{
+		{0, 1, xWheelUp},
+		{0, -1, xWheelDown},
+		{1, 0, xWheelRight},
+		{-1, 0, xWheelLeft},
+	}
+	for _, w := range wheel {
+		if err := MoveMouseWheelBy(w.dx, w.dy); err != nil {
+			t.Fatal(err)
+		}
+		e := waitForWindowEvent(t, c, xButtonPressEvent, window)
+		if e[1] != w.button {
+			t.Errorf("wheel %v,%v: want button %d but got %d", w.dx, w.dy, w.button, e[1])
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package auto
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestX11Monitors(t *testing.T) {
+	c := testDisplay(t)
+
+	monitors, err := Monitors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(monitors) == 0 {
+		t.Fatal("there are no monitors")
+	}
+	screen := Rectangle{Width: c.screen.width, Height: c.screen.height}
+	for _, m := range monitors {
+		if m.Width <= 0 || m.Height <= 0 {
+			t.Errorf("monitor %+v is empty", m)
+		}
+		if intersect(m.Rectangle, screen) != m.Rectangle {
+			t.Errorf("monitor %+v is not on the screen %+v", m, screen)
+		}
+		if intersect(m.WorkArea, m.Rectangle) != m.WorkArea {
+			t.Errorf("the work area of monitor %+v is not inside the monitor", m)
+		}
+	}
+
+	primary, err := PrimaryMonitor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !primary.Primary {
+		t.Errorf("PrimaryMonitor returned %+v which is not primary", primary)
+	}
+}
+
+func TestX11CaptureScreen(t *testing.T) {
+	c := testDisplay(t)
+	if c.screen.depth != 24 && c.screen.depth != 32 {
+		t.Skipf("the screen depth is %d, run Xvfb with 24 bits per pixel", c.screen.depth)
+	}
+	// In TrueColor visuals with 24 bits the pixel value is 0xRRGGBB.
+	orange := color.RGBA{R: 0xFF, G: 0x80, A: 0xFF}
+	createTestWindow(t, c, testWindow{
+		Rectangle:        Rectangle{X: 10, Y: 20, Width: 30, Height: 40},
+		background:       0xFF8000,
+		overrideRedirect: true,
+	})
+
+	img, err := CaptureScreen(0, 0, 100, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 100, 100) {
+		t.Errorf("want bounds 0,0,100,100 but have %v", img.Bounds())
+	}
+	for _, p := range []image.Point{{10, 20}, {39, 20}, {10, 59}, {39, 59}} {
+		if got := img.At(p.X, p.Y); got != orange {
+			t.Errorf("want the window color at %v but have %v", p, got)
+		}
+	}
+
+	// The image keeps the virtual screen coordinates, parts that are not on
+	// the screen are transparent.
+	img, err = CaptureScreen(-5, 25, 20, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != image.Rect(-5, 25, 15, 35) {
+		t.Errorf("want bounds -5,25,15,35 but have %v", img.Bounds())
+	}
+	if got := img.At(-1, 30); got != (color.RGBA{}) {
+		t.Errorf("want transparent pixels off the screen but have %v", got)
+	}
+	if got := img.At(12, 30); got != orange {
+		t.Errorf("want the window color at 12,30 but have %v", got)
+	}
+}
+
+func TestX11CaptureScreenShm(t *testing.T) {
+	c := testDisplay(t)
+	createTestWindow(t, c, testWindow{
+		Rectangle:        Rectangle{X: 10, Y: 20, Width: 30, Height: 40},
+		background:       0xFF8000,
+		overrideRedirect: true,
+	})
+
+	r := Rectangle{X: 0, Y: 0, Width: 100, Height: 100}
+	shmDepth, shmData, err := c.getImageShm(r)
+	if err != nil {
+		t.Skipf("MIT-SHM is not available: %v", err)
+	}
+	depth, data, err := c.getImage(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shmDepth != depth || !bytes.Equal(shmData, data) {
+		t.Error("MIT-SHM and GetImage return different images")
+	}
+}
@@ -0,0 +1,135 @@
+package auto
+
+import (
+	"testing"
+	"time"
+)
+
+func TestX11Windows(t *testing.T) {
+	c := testDisplay(t)
+	handle := createManagedTestWindow(t, c)
+
+	var w Window
+	found := eventually(func() bool {
+		windows, err := Windows()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, win := range windows {
+			if win.Handle == uintptr(handle) {
+				w = win
+				return true
+			}
+		}
+		return false
+	})
+	if !found {
+		t.Fatal("the window manager does not list our window")
+	}
+	if w.Title != "auto test window" {
+		t.Errorf("want title %q but have %q", "auto test window", w.Title)
+	}
+	if w.ClassName != "AutoTest" {
+		t.Errorf("want class name %q but have %q", "AutoTest", w.ClassName)
+	}
+	if !w.Visible {
+		t.Error("the window is not visible")
+	}
+	if intersect(w.Content, w.Rectangle) != w.Content {
+		t.Errorf("the content %+v is not inside the window %+v", w.Content, w.Rectangle)
+	}
+
+	if err := w.BringToForeground(); err != nil {
+		t.Fatal(err)
+	}
+	if !eventually(func() bool {
+		fg, err := ForegroundWindow()
+		return err == nil && fg.Handle == w.Handle
+	}) {
+		t.Error("the window did not come to the foreground")
+	}
+}
+
+func TestX11WindowStates(t *testing.T) {
+	c := testDisplay(t)
+	w := windowHandleToWindow(createManagedTestWindow(t, c))
+
+	states := []struct {
+		name   string
+		change func()
+		want   func() bool
+	}{
+		{"maximize", w.Maximize, func() bool { return w.Maximized }},
+		{"restore from maximized", w.Restore, func() bool { return !w.Maximized }},
+		{"minimize", w.Minimize, func() bool { return w.Minimized }},
+		{"restore from minimized", w.Restore, func() bool { return !w.Minimized && w.Visible }},
+		{"hide", w.Hide, func() bool { return !w.Visible }},
+		{"show", w.Show, func() bool { return w.Visible }},
+	}
+	for _, s := range states {
+		s.change()
+		if !eventually(func() bool { w.Update(); return s.want() }) {
+			t.Errorf("%s: the window has the wrong state %+v", s.name, w)
+		}
+	}
+}
+
+func TestX11WindowPosition(t *testing.T) {
+	c := testDisplay(t)
+	w := windowHandleToWindow(createManagedTestWindow(t, c))
+
+	hasPosition := func(position func() (x, y, width, height int, err error), want Rectangle) bool {
+		return eventually(func() bool {
+			x, y, width, height, err := position()
+			return err == nil && (Rectangle{x, y, width, height}) == want
+		})
+	}
+
+	inner := Rectangle{X: 50, Y: 60, Width: 320, Height: 240}
+	if err := w.SetInnerPosition(inner.X, inner.Y, inner.Width, inner.Height); err != nil {
+		t.Fatal(err)
+	}
+	if !hasPosition(w.InnerPosition, inner) {
+		t.Errorf("the window content is not at %+v", inner)
+	}
+
+	outer := Rectangle{X: 80, Y: 90, Width: 400, Height: 300}
+	if err := w.SetOuterPosition(outer.X, outer.Y, outer.Width, outer.Height); err != nil {
+		t.Fatal(err)
+	}
+	if !hasPosition(w.OuterPosition, outer) {
+		t.Errorf("the window is not at %+v", outer)
+	}
+}
+
+// createManagedTestWindow creates a window that the window manager manages.
+// The test is skipped if no window manager supports EWMH.
+func createManagedTestWindow(t *testing.T, c *xConn) uint32 {
+	t.Helper()
+	check, err := c.atom("_NET_SUPPORTING_WM_CHECK")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prop, err := c.getProperty(c.screen.root, check, xAtomWindow, false)
+	if err != nil || len(prop.uint32s()) == 0 {
+		t.Skip("no window manager supports EWMH, run one in the X server")
+	}
+	return createTestWindow(t, c, testWindow{
+		Rectangle: Rectangle{X: 100, Y: 100, Width: 300, Height: 200},
+		title:     "auto test window",
+		class:     "AutoTest",
+	})
+}
+
+// eventually calls f until it returns true or a few seconds have passed. Window
+// managers handle our requests asynchronously.
+func eventually(f func() bool) bool {
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if f() {
+			return true
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return f()
+}